@@ -0,0 +1,76 @@
+package wavgo
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// ACIDChunkID is the ID of the ACID loop information chunk.
+const ACIDChunkID = "acid"
+
+// Flags of the ACID chunk.
+const (
+	ACIDOneShot    = 0x01 // the file is a one-shot rather than a loop
+	ACIDRootNote   = 0x02 // RootNote is valid
+	ACIDStretch    = 0x04 // the file may be time-stretched
+	ACIDDiskBased  = 0x08 // the file is streamed from disk
+	ACIDHighOctave = 0x10 // the root note is an octave higher (ACIDizer flag)
+)
+
+// ACID holds the contents of an acid chunk used by DAWs for tempo sync.
+type ACID struct {
+	// Flags is a combination of the ACID* flag constants.
+	Flags uint32
+
+	// RootNote is the MIDI note of the loop's key (60 is C), valid if
+	// Flags has ACIDRootNote set.
+	RootNote uint16
+
+	// NumBeats is the length of the loop in beats.
+	NumBeats uint32
+
+	// MeterDenominator and MeterNumerator give the time signature, e.g.
+	// 4 and 4 for 4/4.
+	MeterDenominator uint16
+	MeterNumerator   uint16
+
+	// Tempo is the tempo in beats per minute.
+	Tempo float32
+}
+
+// IsOneShot reports whether the file is a one-shot rather than a loop.
+func (a *ACID) IsOneShot() bool {
+	return a.Flags&ACIDOneShot != 0
+}
+
+// parseACID decodes the 24-byte payload of an acid chunk.
+func parseACID(data []byte) (*ACID, error) {
+	if len(data) < 24 {
+		return nil, invalidChunk(ACIDChunkID, "too short")
+	}
+	le := binary.LittleEndian
+	return &ACID{
+		Flags:    le.Uint32(data[0:4]),
+		RootNote: le.Uint16(data[4:6]),
+		// data[6:12] holds two fields of unknown meaning.
+		NumBeats:         le.Uint32(data[12:16]),
+		MeterDenominator: le.Uint16(data[16:18]),
+		MeterNumerator:   le.Uint16(data[18:20]),
+		Tempo:            math.Float32frombits(le.Uint32(data[20:24])),
+	}, nil
+}
+
+// encodeACID returns the 24-byte payload of an acid chunk.
+func encodeACID(a *ACID) []byte {
+	le := binary.LittleEndian
+	data := make([]byte, 24)
+	le.PutUint32(data[0:4], a.Flags)
+	le.PutUint16(data[4:6], a.RootNote)
+	le.PutUint16(data[6:8], 0x8000)
+	le.PutUint32(data[8:12], 0)
+	le.PutUint32(data[12:16], a.NumBeats)
+	le.PutUint16(data[16:18], a.MeterDenominator)
+	le.PutUint16(data[18:20], a.MeterNumerator)
+	le.PutUint32(data[20:24], math.Float32bits(a.Tempo))
+	return data
+}
@@ -0,0 +1,65 @@
+package wavgo
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestACIDRoundTrip(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   2,
+		SampleRate:    44100,
+		ByteRate:      176400,
+		BlockAlign:    4,
+		BitsPerSample: 16,
+	}
+	acid := &ACID{
+		Flags:            ACIDRootNote | ACIDStretch,
+		RootNote:         57,
+		NumBeats:         8,
+		MeterDenominator: 4,
+		MeterNumerator:   4,
+		Tempo:            128.5,
+	}
+
+	filename := "testdata/TestACIDRoundTrip.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetACID(acid)
+	require.NoError(t, w.WriteSamples(make([]Sample, 4)))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, acid, r.GetACID())
+	require.False(t, r.GetACID().IsOneShot())
+}
+
+func TestACIDParse(t *testing.T) {
+	data := []byte{
+		0x01, 0x00, 0x00, 0x00, // flags: one-shot
+		0x3C, 0x00, // root note
+		0x00, 0x80, 0x00, 0x00, 0x00, 0x00, // unknown
+		0x04, 0x00, 0x00, 0x00, // beats
+		0x04, 0x00, // meter denominator
+		0x03, 0x00, // meter numerator
+		0x00, 0x00, 0xF0, 0x42, // tempo: 120.0
+	}
+	acid, err := parseACID(data)
+	require.NoError(t, err)
+	require.True(t, acid.IsOneShot())
+	require.Equal(t, uint16(60), acid.RootNote)
+	require.Equal(t, uint32(4), acid.NumBeats)
+	require.Equal(t, uint16(3), acid.MeterNumerator)
+	require.Equal(t, float32(120), acid.Tempo)
+	require.Equal(t, data, encodeACID(acid))
+
+	_, err = parseACID(data[:23])
+	require.EqualError(t, err, "invalid acid chunk: too short")
+}
@@ -0,0 +1,146 @@
+package wavgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+)
+
+const (
+	// AXMLChunkID is the ID of the chunk holding ADM XML metadata (ITU-R BS.2076).
+	AXMLChunkID = "axml"
+	// CHNAChunkID is the ID of the chunk mapping tracks to ADM track UIDs (ITU-R BS.2088).
+	CHNAChunkID = "chna"
+)
+
+// chnaEntrySize is the size of an audio ID entry of a chna chunk.
+const chnaEntrySize = 40
+
+// ChannelAssignment holds the contents of a chna chunk, which maps the
+// tracks of the data chunk to ADM audioTrackUIDs.
+type ChannelAssignment struct {
+	// NumTracks is the number of tracks used. Zero is written as the number
+	// of distinct track indices in AudioIDs.
+	NumTracks uint16
+
+	AudioIDs []AudioID
+}
+
+// AudioID is an entry of a chna chunk.
+type AudioID struct {
+	TrackIndex     uint16 // 1-based track index in the data chunk
+	UID            string // audioTrackUID, e.g. "ATU_00000001"
+	TrackFormatRef string // audioTrackFormatID, e.g. "AT_00031001_01"
+	PackFormatRef  string // audioPackFormatID, e.g. "AP_00031001"
+}
+
+// ADM holds the IDs and names of the main elements of an ADM XML document.
+// The full document is available from Reader.GetADMRaw.
+type ADM struct {
+	Programmes  []ADMElement // audioProgramme
+	Contents    []ADMElement // audioContent
+	Objects     []ADMElement // audioObject
+	PackFormats []ADMElement // audioPackFormat
+	TrackUIDs   []ADMElement // audioTrackUID, which has no name
+}
+
+// ADMElement identifies an element of an ADM document.
+type ADMElement struct {
+	ID   string
+	Name string
+}
+
+// ParseADM extracts the main elements of an ADM XML document.
+func ParseADM(data []byte) (*ADM, error) {
+	adm := &ADM{}
+	targets := map[string]*[]ADMElement{
+		"audioProgramme":  &adm.Programmes,
+		"audioContent":    &adm.Contents,
+		"audioObject":     &adm.Objects,
+		"audioPackFormat": &adm.PackFormats,
+		"audioTrackUID":   &adm.TrackUIDs,
+	}
+	d := xml.NewDecoder(bytes.NewReader(bytes.TrimRight(data, "\x00")))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		list, ok := targets[start.Name.Local]
+		if !ok {
+			continue
+		}
+		elem := ADMElement{}
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case start.Name.Local + "ID", "UID":
+				elem.ID = attr.Value
+			case start.Name.Local + "Name":
+				elem.Name = attr.Value
+			}
+		}
+		// References such as <audioObjectIDRef> share no names with the
+		// elements themselves, so every match here is a definition.
+		*list = append(*list, elem)
+	}
+	return adm, nil
+}
+
+// parseChannelAssignment decodes the payload of a chna chunk.
+func parseChannelAssignment(data []byte) (*ChannelAssignment, error) {
+	if len(data) < 4 {
+		return nil, invalidChunk(CHNAChunkID, "too short")
+	}
+	le := binary.LittleEndian
+	numUIDs := int(le.Uint16(data[2:4]))
+	if numUIDs*chnaEntrySize > len(data)-4 {
+		return nil, invalidChunk(CHNAChunkID, "entries exceed chunk size")
+	}
+	c := &ChannelAssignment{
+		NumTracks: le.Uint16(data[0:2]),
+		AudioIDs:  make([]AudioID, numUIDs),
+	}
+	for i := range c.AudioIDs {
+		p := data[4+i*chnaEntrySize:]
+		c.AudioIDs[i] = AudioID{
+			TrackIndex:     le.Uint16(p[0:2]),
+			UID:            fixedString(p[2:14]),
+			TrackFormatRef: fixedString(p[14:28]),
+			PackFormatRef:  fixedString(p[28:39]),
+		}
+	}
+	return c, nil
+}
+
+// encodeChannelAssignment returns the payload of a chna chunk.
+func encodeChannelAssignment(c *ChannelAssignment) []byte {
+	le := binary.LittleEndian
+	numTracks := c.NumTracks
+	if numTracks == 0 {
+		tracks := make(map[uint16]bool)
+		for _, id := range c.AudioIDs {
+			tracks[id.TrackIndex] = true
+		}
+		numTracks = uint16(len(tracks))
+	}
+	data := make([]byte, 4+chnaEntrySize*len(c.AudioIDs))
+	le.PutUint16(data[0:2], numTracks)
+	le.PutUint16(data[2:4], uint16(len(c.AudioIDs)))
+	for i, id := range c.AudioIDs {
+		p := data[4+i*chnaEntrySize:]
+		le.PutUint16(p[0:2], id.TrackIndex)
+		putFixedString(p[2:14], id.UID)
+		putFixedString(p[14:28], id.TrackFormatRef)
+		putFixedString(p[28:39], id.PackFormatRef)
+		// p[39] is a pad byte.
+	}
+	return data
+}
@@ -0,0 +1,95 @@
+package wavgo
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testADM = `<?xml version="1.0" encoding="UTF-8"?>
+<ebuCoreMain xmlns="urn:ebu:metadata-schema:ebuCore_2016">
+  <coreMetadata><format><audioFormatExtended>
+    <audioProgramme audioProgrammeID="APR_1001" audioProgrammeName="Main">
+      <audioContentIDRef>ACO_1001</audioContentIDRef>
+    </audioProgramme>
+    <audioContent audioContentID="ACO_1001" audioContentName="Dialogue">
+      <audioObjectIDRef>AO_1001</audioObjectIDRef>
+    </audioContent>
+    <audioObject audioObjectID="AO_1001" audioObjectName="Stereo bed">
+      <audioPackFormatIDRef>AP_00010002</audioPackFormatIDRef>
+      <audioTrackUIDRef>ATU_00000001</audioTrackUIDRef>
+      <audioTrackUIDRef>ATU_00000002</audioTrackUIDRef>
+    </audioObject>
+    <audioTrackUID UID="ATU_00000001" sampleRate="48000" bitDepth="24"/>
+    <audioTrackUID UID="ATU_00000002" sampleRate="48000" bitDepth="24"/>
+  </audioFormatExtended></format></coreMetadata>
+</ebuCoreMain>
+`
+
+func TestParseADM(t *testing.T) {
+	adm, err := ParseADM([]byte(testADM))
+	require.NoError(t, err)
+	require.Equal(t, []ADMElement{{ID: "APR_1001", Name: "Main"}}, adm.Programmes)
+	require.Equal(t, []ADMElement{{ID: "ACO_1001", Name: "Dialogue"}}, adm.Contents)
+	require.Equal(t, []ADMElement{{ID: "AO_1001", Name: "Stereo bed"}}, adm.Objects)
+	require.Empty(t, adm.PackFormats)
+	require.Equal(t, []ADMElement{{ID: "ATU_00000001"}, {ID: "ATU_00000002"}}, adm.TrackUIDs)
+
+	_, err = ParseADM([]byte("<ebuCoreMain><audioProgramme>"))
+	require.Error(t, err)
+}
+
+func TestADMRoundTrip(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   2,
+		SampleRate:    48000,
+		ByteRate:      288000,
+		BlockAlign:    6,
+		BitsPerSample: 24,
+	}
+	chna := &ChannelAssignment{
+		NumTracks: 2,
+		AudioIDs: []AudioID{
+			{TrackIndex: 1, UID: "ATU_00000001", TrackFormatRef: "AT_00010001_01", PackFormatRef: "AP_00010002"},
+			{TrackIndex: 2, UID: "ATU_00000002", TrackFormatRef: "AT_00010002_01", PackFormatRef: "AP_00010002"},
+		},
+	}
+	doc := []byte(testADM)
+	require.Zero(t, len(doc)%2)
+
+	filename := "testdata/TestADMRoundTrip.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetChannelAssignment(chna)
+	w.SetADM(doc)
+	require.NoError(t, w.WriteSamples(make([]Sample, 2)))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, chna, r.GetChannelAssignment())
+	require.Equal(t, doc, r.GetADMRaw())
+	adm, err := r.GetADM()
+	require.NoError(t, err)
+	require.Len(t, adm.TrackUIDs, 2)
+}
+
+func TestChannelAssignmentEncode(t *testing.T) {
+	data := encodeChannelAssignment(&ChannelAssignment{AudioIDs: []AudioID{
+		{TrackIndex: 1, UID: "ATU_00000001"},
+		{TrackIndex: 1, UID: "ATU_00000002"},
+	}})
+	require.Len(t, data, 4+2*chnaEntrySize)
+	require.Equal(t, []byte{0x01, 0x00, 0x02, 0x00}, data[0:4])
+	require.Equal(t, []byte("ATU_00000002"), data[4+chnaEntrySize+2:4+chnaEntrySize+14])
+
+	_, err := parseChannelAssignment(data[:3])
+	require.EqualError(t, err, "invalid chna chunk: too short")
+	_, err = parseChannelAssignment(data[:len(data)-1])
+	require.EqualError(t, err, "invalid chna chunk: entries exceed chunk size")
+}
@@ -0,0 +1,105 @@
+package wavgo
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// BEXTChunkID is the ID of the Broadcast Wave Format extension chunk.
+const BEXTChunkID = "bext"
+
+// bextFixedSize is the size of the fixed-length part of a bext chunk.
+const bextFixedSize = 602
+
+// BroadcastExtension holds the contents of a Broadcast Wave Format bext chunk
+// as defined by EBU Tech 3285. Text fields are ASCII and are truncated to
+// their fixed width when written.
+type BroadcastExtension struct {
+	Description         string // up to 256 characters
+	Originator          string // up to 32 characters
+	OriginatorReference string // up to 32 characters
+	OriginationDate     string // yyyy-mm-dd
+	OriginationTime     string // hh-mm-ss
+
+	// TimeReference is the sample count since midnight of the first sample.
+	TimeReference uint64
+
+	// Version is the version of the bext chunk. Loudness fields are only
+	// meaningful for version 2 and later.
+	Version uint16
+
+	// UMID is the SMPTE 330M Unique Material Identifier.
+	UMID [64]byte
+
+	// Loudness values are stored in hundredths, e.g. -2300 for -23.00 LUFS.
+	LoudnessValue        int16
+	LoudnessRange        int16
+	MaxTruePeakLevel     int16
+	MaxMomentaryLoudness int16
+	MaxShortTermLoudness int16
+
+	// CodingHistory describes the coding processes applied to the audio.
+	CodingHistory string
+}
+
+// parseBroadcastExtension decodes the payload of a bext chunk.
+func parseBroadcastExtension(data []byte) (*BroadcastExtension, error) {
+	if len(data) < bextFixedSize {
+		return nil, invalidChunk(BEXTChunkID, "too short")
+	}
+	le := binary.LittleEndian
+	b := &BroadcastExtension{
+		Description:          fixedString(data[0:256]),
+		Originator:           fixedString(data[256:288]),
+		OriginatorReference:  fixedString(data[288:320]),
+		OriginationDate:      fixedString(data[320:330]),
+		OriginationTime:      fixedString(data[330:338]),
+		TimeReference:        uint64(le.Uint32(data[338:342])) | uint64(le.Uint32(data[342:346]))<<32,
+		Version:              le.Uint16(data[346:348]),
+		LoudnessValue:        int16(le.Uint16(data[412:414])),
+		LoudnessRange:        int16(le.Uint16(data[414:416])),
+		MaxTruePeakLevel:     int16(le.Uint16(data[416:418])),
+		MaxMomentaryLoudness: int16(le.Uint16(data[418:420])),
+		MaxShortTermLoudness: int16(le.Uint16(data[420:422])),
+		CodingHistory:        fixedString(data[bextFixedSize:]),
+	}
+	copy(b.UMID[:], data[348:412])
+	return b, nil
+}
+
+// encodeBroadcastExtension returns the payload of a bext chunk.
+func encodeBroadcastExtension(b *BroadcastExtension) []byte {
+	data := make([]byte, bextFixedSize+len(b.CodingHistory))
+	le := binary.LittleEndian
+	putFixedString(data[0:256], b.Description)
+	putFixedString(data[256:288], b.Originator)
+	putFixedString(data[288:320], b.OriginatorReference)
+	putFixedString(data[320:330], b.OriginationDate)
+	putFixedString(data[330:338], b.OriginationTime)
+	le.PutUint32(data[338:342], uint32(b.TimeReference))
+	le.PutUint32(data[342:346], uint32(b.TimeReference>>32))
+	le.PutUint16(data[346:348], b.Version)
+	copy(data[348:412], b.UMID[:])
+	le.PutUint16(data[412:414], uint16(b.LoudnessValue))
+	le.PutUint16(data[414:416], uint16(b.LoudnessRange))
+	le.PutUint16(data[416:418], uint16(b.MaxTruePeakLevel))
+	le.PutUint16(data[418:420], uint16(b.MaxMomentaryLoudness))
+	le.PutUint16(data[420:422], uint16(b.MaxShortTermLoudness))
+	// data[422:602] is reserved and must be zero.
+	copy(data[bextFixedSize:], b.CodingHistory)
+	return data
+}
+
+// fixedString decodes a NUL-padded fixed-width ASCII field.
+func fixedString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// putFixedString encodes s into the fixed-width field dst, truncating it
+// if it is too long. The remaining bytes of dst are left as zero.
+func putFixedString(dst []byte, s string) {
+	copy(dst, s)
+}
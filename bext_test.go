@@ -0,0 +1,72 @@
+package wavgo
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastExtensionRoundTrip(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   2,
+		SampleRate:    48000,
+		ByteRate:      192000,
+		BlockAlign:    4,
+		BitsPerSample: 16,
+	}
+	bext := &BroadcastExtension{
+		Description:          "Interview, studio 2",
+		Originator:           "wavgo",
+		OriginatorReference:  "WAVGO0000000001",
+		OriginationDate:      "2024-05-06",
+		OriginationTime:      "12-34-56",
+		TimeReference:        0x1_0000_0001,
+		Version:              2,
+		LoudnessValue:        -2300,
+		LoudnessRange:        540,
+		MaxTruePeakLevel:     -100,
+		MaxMomentaryLoudness: -1850,
+		MaxShortTermLoudness: -2010,
+		CodingHistory:        "A=PCM,F=48000,W=16,M=stereo,T=wavgo;\r\n",
+	}
+	copy(bext.UMID[:], "umid")
+
+	filename := "testdata/TestBroadcastExtensionRoundTrip.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetBroadcastExtension(bext)
+	require.NoError(t, w.WriteSamples([]Sample{{1, 2}}))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, bext, r.GetBroadcastExtension())
+}
+
+func TestBroadcastExtensionFixedFields(t *testing.T) {
+	data := encodeBroadcastExtension(&BroadcastExtension{
+		Description:     strings.Repeat("d", 300),
+		OriginationDate: "2024-05-06",
+		TimeReference:   48000,
+	})
+	require.Len(t, data, bextFixedSize)
+	require.Equal(t, []byte("2024-05-06"), data[320:330])
+	require.Equal(t, []byte{0x80, 0xBB, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, data[338:346])
+
+	got, err := parseBroadcastExtension(data)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("d", 256), got.Description)
+	require.Equal(t, uint64(48000), got.TimeReference)
+	require.Empty(t, got.CodingHistory)
+}
+
+func TestBroadcastExtensionTooShort(t *testing.T) {
+	_, err := parseBroadcastExtension(make([]byte, bextFixedSize-1))
+	require.EqualError(t, err, "invalid bext chunk: too short")
+}
@@ -0,0 +1,127 @@
+package wavgo
+
+import "encoding/binary"
+
+// CARTChunkID is the ID of the AES46 radio traffic data chunk.
+const CARTChunkID = "cart"
+
+// cartFixedSize is the size of the fixed-length part of a cart chunk.
+const cartFixedSize = 2048
+
+// Cart holds the contents of an AES46 cart chunk used by radio automation
+// systems. Text fields are ASCII and are truncated to their fixed width
+// when written.
+type Cart struct {
+	Version            string // four digits, e.g. "0101" for version 1.01
+	Title              string // up to 64 characters
+	Artist             string // up to 64 characters
+	CutID              string // up to 64 characters
+	ClientID           string // up to 64 characters
+	Category           string // up to 64 characters
+	Classification     string // up to 64 characters
+	OutCue             string // up to 64 characters
+	StartDate          string // yyyy/mm/dd
+	StartTime          string // hh:mm:ss
+	EndDate            string // yyyy/mm/dd
+	EndTime            string // hh:mm:ss
+	ProducerAppID      string // up to 64 characters
+	ProducerAppVersion string // up to 64 characters
+	UserDef            string // up to 64 characters
+
+	// LevelReference is the sample value corresponding to 0 dB reference.
+	LevelReference int32
+
+	// PostTimers marks positions in the audio such as intro and segue points.
+	PostTimers [8]CartTimer
+
+	URL string // up to 1024 characters
+
+	// TagText is free-form text following the fixed fields.
+	TagText string
+}
+
+// CartTimer is a post timer of a cart chunk.
+type CartTimer struct {
+	// Usage is a four character code such as "INT1" or "SEG1". An empty
+	// usage marks an unused timer.
+	Usage string
+
+	// Value is the timer position in sample frames.
+	Value uint32
+}
+
+// cartTextField is a fixed-width text field of a cart chunk.
+type cartTextField struct {
+	value *string
+	width int
+}
+
+func (c *Cart) textFields() []cartTextField {
+	return []cartTextField{
+		{&c.Version, 4},
+		{&c.Title, 64},
+		{&c.Artist, 64},
+		{&c.CutID, 64},
+		{&c.ClientID, 64},
+		{&c.Category, 64},
+		{&c.Classification, 64},
+		{&c.OutCue, 64},
+		{&c.StartDate, 10},
+		{&c.StartTime, 8},
+		{&c.EndDate, 10},
+		{&c.EndTime, 8},
+		{&c.ProducerAppID, 64},
+		{&c.ProducerAppVersion, 64},
+		{&c.UserDef, 64},
+	}
+}
+
+// Offsets of the fields following the leading text fields.
+const (
+	cartLevelReferenceOffset = 680
+	cartPostTimerOffset      = 684
+	cartURLOffset            = 1024
+)
+
+// parseCart decodes the payload of a cart chunk.
+func parseCart(data []byte) (*Cart, error) {
+	if len(data) < cartFixedSize {
+		return nil, invalidChunk(CARTChunkID, "too short")
+	}
+	le := binary.LittleEndian
+	c := &Cart{}
+	off := 0
+	for _, f := range c.textFields() {
+		*f.value = fixedString(data[off : off+f.width])
+		off += f.width
+	}
+	c.LevelReference = int32(le.Uint32(data[cartLevelReferenceOffset:]))
+	for i := range c.PostTimers {
+		p := data[cartPostTimerOffset+i*8:]
+		c.PostTimers[i] = CartTimer{Usage: fixedString(p[0:4]), Value: le.Uint32(p[4:8])}
+	}
+	c.URL = fixedString(data[cartURLOffset:cartFixedSize])
+	c.TagText = fixedString(data[cartFixedSize:])
+	return c, nil
+}
+
+// encodeCart returns the payload of a cart chunk.
+func encodeCart(c *Cart) []byte {
+	le := binary.LittleEndian
+	data := make([]byte, cartFixedSize+len(c.TagText))
+	off := 0
+	for _, f := range c.textFields() {
+		putFixedString(data[off:off+f.width], *f.value)
+		off += f.width
+	}
+	le.PutUint32(data[cartLevelReferenceOffset:], uint32(c.LevelReference))
+	for i, t := range c.PostTimers {
+		p := data[cartPostTimerOffset+i*8:]
+		putFixedString(p[0:4], t.Usage)
+		le.PutUint32(p[4:8], t.Value)
+	}
+	// data[748:1024] is reserved and must be zero.
+	putFixedString(data[cartURLOffset:cartFixedSize], c.URL)
+	copy(data[cartFixedSize:], c.TagText)
+	return data
+}
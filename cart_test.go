@@ -0,0 +1,84 @@
+package wavgo
+
+import (
+	"encoding/binary"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCartRoundTrip(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   2,
+		SampleRate:    44100,
+		ByteRate:      176400,
+		BlockAlign:    4,
+		BitsPerSample: 16,
+	}
+	cart := &Cart{
+		Version:            "0101",
+		Title:              "Spring Sale",
+		Artist:             "Station Voice",
+		CutID:              "12345",
+		ClientID:           "ACME",
+		Category:           "COMM",
+		Classification:     "Retail",
+		OutCue:             "...at ACME today",
+		StartDate:          "2024/03/01",
+		StartTime:          "00:00:00",
+		EndDate:            "2024/03/31",
+		EndTime:            "23:59:59",
+		ProducerAppID:      "wavgo",
+		ProducerAppVersion: "1.0",
+		UserDef:            "spot",
+		LevelReference:     32768,
+		URL:                "https://example.com/spots/12345",
+		TagText:            "<cart />\r\n",
+	}
+	cart.PostTimers[0] = CartTimer{Usage: "INT1", Value: 44100}
+	cart.PostTimers[1] = CartTimer{Usage: "SEG1", Value: 88200}
+
+	filename := "testdata/TestCartRoundTrip.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetCart(cart)
+	require.NoError(t, w.WriteSamples(make([]Sample, 2)))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, cart, r.GetCart())
+}
+
+func TestCartFixedFields(t *testing.T) {
+	cart := &Cart{
+		Version:        "0101",
+		Title:          strings.Repeat("t", 70),
+		EndTime:        "23:59:59",
+		LevelReference: -1,
+		URL:            "u",
+	}
+	cart.PostTimers[7] = CartTimer{Usage: "AUXO", Value: 7}
+	data := encodeCart(cart)
+	require.Len(t, data, cartFixedSize)
+	require.Equal(t, []byte("0101"), data[0:4])
+	require.Equal(t, []byte("23:59:59"), data[480:488])
+	require.Equal(t, uint32(0xFFFFFFFF), binary.LittleEndian.Uint32(data[680:684]))
+	require.Equal(t, []byte("AUXO"), data[740:744])
+	require.Equal(t, byte('u'), data[1024])
+
+	got, err := parseCart(data)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("t", 64), got.Title)
+	require.Equal(t, int32(-1), got.LevelReference)
+	require.Equal(t, CartTimer{Usage: "AUXO", Value: 7}, got.PostTimers[7])
+
+	_, err = parseCart(data[:cartFixedSize-1])
+	require.EqualError(t, err, "invalid cart chunk: too short")
+}
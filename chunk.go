@@ -0,0 +1,91 @@
+package wavgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/takurooo/wavgo/internal/binio"
+	"github.com/takurooo/wavgo/internal/riff"
+)
+
+// Chunk describes a chunk of a WAV file as returned by Reader.GetChunks.
+type Chunk struct {
+	// ID is the four character chunk ID, e.g. "LIST" or "bext".
+	ID string
+
+	// Size is the size field of the chunk header. For the data chunk of an
+	// RF64/BW64 file it is 0xFFFFFFFF; use Len for the actual size.
+	Size uint32
+
+	// Offset is the position of the chunk header from the start of the file.
+	// The chunk data starts at Offset+8.
+	Offset int64
+
+	length int64
+	src    io.ReaderAt
+}
+
+// Len returns the length of the chunk data in bytes.
+func (c *Chunk) Len() int64 {
+	return c.length
+}
+
+// Data reads the chunk data from the underlying file. The data is read on
+// every call, so large chunks are only loaded when needed.
+func (c *Chunk) Data() ([]byte, error) {
+	data := make([]byte, c.length)
+	if _, err := io.ReadFull(io.NewSectionReader(c.src, c.Offset+8, c.length), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ChunkPlacement selects where Writer.AddChunk places a chunk.
+type ChunkPlacement int
+
+const (
+	// BeforeData places the chunk between the fmt and data chunks.
+	BeforeData ChunkPlacement = iota
+	// AfterData places the chunk after the data chunk.
+	AfterData
+)
+
+// customChunk is a chunk added with Writer.AddChunk.
+type customChunk struct {
+	id        string
+	data      []byte
+	placement ChunkPlacement
+}
+
+// validateChunkID checks that id can be added with Writer.AddChunk. The
+// chunks the Writer maintains itself and the headers of RF64/BW64 files
+// cannot be added.
+func validateChunkID(id string) error {
+	if len(id) != 4 {
+		return fmt.Errorf("%w: must be exactly 4 characters", ErrInvalidChunkID)
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x20 || 0x7E < id[i] {
+			return fmt.Errorf("%w %q: must be printable ASCII", ErrInvalidChunkID, id)
+		}
+	}
+	switch id {
+	case riff.RIFFChunkID, riff.FMTChunkID, riff.DATAChunkID:
+		return fmt.Errorf("%w %q: written by the Writer", ErrInvalidChunkID, id)
+	case riff.RF64ChunkID, riff.BW64ChunkID, riff.DS64ChunkID:
+		return fmt.Errorf("%w %q: reserved for RF64/BW64 files", ErrInvalidChunkID, id)
+	}
+	return nil
+}
+
+// writeChunkTo writes a chunk header and data followed by a pad byte if the
+// data has an odd length.
+func writeChunkTo(bw *binio.Writer, id string, data []byte) {
+	bw.WriteS32(id, binary.BigEndian)
+	bw.WriteU32(uint32(len(data)), binary.LittleEndian)
+	bw.WriteRaw(data)
+	if len(data)%2 == 1 {
+		bw.WriteU8(0)
+	}
+}
@@ -0,0 +1,125 @@
+package wavgo
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderGetChunks(t *testing.T) {
+	r := NewReader()
+	require.NoError(t, r.Open("testdata/read_test.wav"))
+	defer r.Close()
+	require.NoError(t, r.Load())
+
+	chunks := r.GetChunks()
+	require.Len(t, chunks, 2)
+	require.Equal(t, "fmt ", chunks[0].ID)
+	require.Equal(t, uint32(16), chunks[0].Size)
+	require.Equal(t, int64(12), chunks[0].Offset)
+	require.Equal(t, "data", chunks[1].ID)
+	require.Equal(t, int64(36), chunks[1].Offset)
+	require.Equal(t, int64(8), chunks[1].Len())
+
+	data, err := chunks[1].Data()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x00, 0x02, 0x00, 0x03, 0x00, 0x04, 0x00}, data)
+}
+
+func TestReaderGetChunksLazy(t *testing.T) {
+	// The unknown chunk after the data chunk is cut short. Load does not
+	// read it, so only Data fails.
+	b := buildWAV(
+		testChunk{"fmt ", pcm16FmtData(1, 8000)},
+		testChunk{"data", []byte{0x01, 0x00}},
+		testChunk{"xbig", []byte("12345678")},
+	)
+	r := &Reader{src: bytes.NewReader(b[:len(b)-4])}
+	require.NoError(t, r.Load())
+	require.Nil(t, r.chunks[2].Data)
+
+	chunks := r.GetChunks()
+	require.Equal(t, "xbig", chunks[2].ID)
+	require.Equal(t, int64(8), chunks[2].Len())
+	_, err := chunks[2].Data()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestWriterAddChunk(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    8000,
+		ByteRate:      16000,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+
+	filename := "testdata/TestWriterAddChunk.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	require.NoError(t, w.AddChunk("xprv", []byte("before"), BeforeData))
+	require.NoError(t, w.AddChunk("xend", []byte("after!"), AfterData))
+	require.NoError(t, w.AddChunk("xemp", nil, BeforeData))
+	require.NoError(t, w.WriteSamples([]Sample{{1, 0}, {2, 0}}))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+
+	chunks := r.GetChunks()
+	ids := make([]string, len(chunks))
+	for i, c := range chunks {
+		ids[i] = c.ID
+	}
+	require.Equal(t, []string{"fmt ", "xprv", "xemp", "data", "xend"}, ids)
+
+	data, err := chunks[1].Data()
+	require.NoError(t, err)
+	require.Equal(t, []byte("before"), data)
+	data, err = chunks[2].Data()
+	require.NoError(t, err)
+	require.Empty(t, data)
+	data, err = chunks[4].Data()
+	require.NoError(t, err)
+	require.Equal(t, []byte("after!"), data)
+
+	samples, err := r.GetSamples(2)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{1, 0}, {2, 0}}, samples)
+}
+
+func TestWriterAddChunkInvalidID(t *testing.T) {
+	w := NewWriter(&Format{})
+	require.EqualError(t, w.AddChunk("abc", nil, BeforeData), "invalid chunk ID: must be exactly 4 characters")
+	require.EqualError(t, w.AddChunk("data", nil, AfterData), `invalid chunk ID "data": written by the Writer`)
+	require.EqualError(t, w.AddChunk("fmt ", nil, BeforeData), `invalid chunk ID "fmt ": written by the Writer`)
+	require.EqualError(t, w.AddChunk("ds64", nil, BeforeData), `invalid chunk ID "ds64": reserved for RF64/BW64 files`)
+	require.EqualError(t, w.AddChunk("BW64", nil, AfterData), `invalid chunk ID "BW64": reserved for RF64/BW64 files`)
+	require.EqualError(t, w.AddChunk("ab\x00c", nil, AfterData), `invalid chunk ID "ab\x00c": must be printable ASCII`)
+}
+
+func TestWriterAddChunkAfterHeader(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "chunks.wav")
+	w := NewWriter(NewPCMFormat(1, 8000, 16))
+	require.NoError(t, w.Open(filename))
+	require.NoError(t, w.WriteSamples([]Sample{{1}}))
+	require.ErrorIs(t, w.AddChunk("xprv", []byte("late"), BeforeData), ErrHeaderWritten)
+	require.NoError(t, w.AddChunk("xend", []byte("late"), AfterData))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	chunks := r.GetChunks()
+	require.Len(t, chunks, 3)
+	require.Equal(t, "xend", chunks[2].ID)
+}
@@ -0,0 +1,47 @@
+// Command wavrepair fixes the headers of WAV files left broken by an
+// interrupted recording, such as a RIFF or data size of 0, and prints a
+// report of the changes made to each file.
+//
+// Usage:
+//
+//	wavrepair file.wav...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/takurooo/wavgo"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: wavrepair file.wav...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	status := 0
+	for _, path := range flag.Args() {
+		report, err := wavgo.Repair(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wavrepair: %s: %v\n", path, err)
+			status = 1
+			continue
+		}
+		if !report.Repaired() {
+			fmt.Printf("%s: %s\n", path, report)
+			continue
+		}
+		fmt.Printf("%s:\n", path)
+		for _, fix := range report.Fixes {
+			fmt.Printf("\t%s\n", fix)
+		}
+	}
+	os.Exit(status)
+}
@@ -0,0 +1,176 @@
+package wavgo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Container identifies the file format that wraps the audio data.
+type Container int
+
+// Containers recognised by DetectContainer.
+const (
+	ContainerUnknown Container = iota
+	ContainerWAVE              // RIFF/WAVE
+	ContainerRIFX              // big-endian RIFX/WAVE
+	ContainerRF64              // EBU RF64/WAVE
+	ContainerBW64              // ITU-R BS.2088 BW64/WAVE
+	ContainerW64               // Sony Wave64
+	ContainerAIFF              // FORM/AIFF
+	ContainerAIFC              // FORM/AIFC
+	ContainerAU                // Sun/NeXT .au
+	ContainerCAF               // Apple Core Audio Format
+)
+
+var containerNames = map[Container]string{
+	ContainerUnknown: "unknown",
+	ContainerWAVE:    "RIFF/WAVE",
+	ContainerRIFX:    "RIFX/WAVE",
+	ContainerRF64:    "RF64/WAVE",
+	ContainerBW64:    "BW64/WAVE",
+	ContainerW64:     "Wave64",
+	ContainerAIFF:    "AIFF",
+	ContainerAIFC:    "AIFF-C",
+	ContainerAU:      "Sun AU",
+	ContainerCAF:     "CAF",
+}
+
+// String returns a human readable name of the container.
+func (c Container) String() string {
+	if name, ok := containerNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Container(%d)", int(c))
+}
+
+// Supported reports whether Open and OpenReader can decode the container.
+func (c Container) Supported() bool {
+	switch c {
+	case ContainerWAVE, ContainerRF64, ContainerBW64:
+		return true
+	}
+	return false
+}
+
+var (
+	w64RIFFGUID = []byte{0x72, 0x69, 0x66, 0x66, 0x2E, 0x91, 0xCF, 0x11, 0xA5, 0xD6, 0x28, 0xDB, 0x04, 0xC1, 0x00, 0x00}
+	w64WAVEGUID = []byte{0x77, 0x61, 0x76, 0x65, 0xF3, 0xAC, 0xD3, 0x11, 0x8C, 0xD1, 0x00, 0xC0, 0x4F, 0x8E, 0xDB, 0x8A}
+)
+
+// DetectContainer sniffs the magic bytes at the start of r and reports the
+// container format. RIFF-style files whose form type is not WAVE (for example
+// AVI or WebP) are rejected with ErrNotWAVE, and unrecognised data is
+// rejected with ErrUnknownContainer.
+func DetectContainer(r io.ReaderAt) (Container, error) {
+	header := make([]byte, 40)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return ContainerUnknown, err
+	}
+	header = header[:n]
+	if len(header) < 4 {
+		return ContainerUnknown, ErrUnknownContainer
+	}
+
+	magic := string(header[0:4])
+	switch magic {
+	case "RIFF", "RIFX", "RF64", "BW64":
+		if len(header) < 12 {
+			return ContainerUnknown, ErrUnknownContainer
+		}
+		if form := string(header[8:12]); form != "WAVE" {
+			return ContainerUnknown, fmt.Errorf("%w: %s form type %q", ErrNotWAVE, magic, form)
+		}
+		switch magic {
+		case "RIFF":
+			return ContainerWAVE, nil
+		case "RIFX":
+			return ContainerRIFX, nil
+		case "RF64":
+			return ContainerRF64, nil
+		default:
+			return ContainerBW64, nil
+		}
+	case "FORM":
+		if len(header) >= 12 {
+			switch string(header[8:12]) {
+			case "AIFF":
+				return ContainerAIFF, nil
+			case "AIFC":
+				return ContainerAIFC, nil
+			}
+		}
+	case ".snd":
+		return ContainerAU, nil
+	case "caff":
+		return ContainerCAF, nil
+	}
+	if len(header) >= 40 && bytes.Equal(header[0:16], w64RIFFGUID) && bytes.Equal(header[24:40], w64WAVEGUID) {
+		return ContainerW64, nil
+	}
+	return ContainerUnknown, ErrUnknownContainer
+}
+
+// AudioReader is the common interface of the readers returned by Open and
+// OpenReader. It is implemented by *Reader.
+type AudioReader interface {
+	// GetFormat returns the audio format of the stream.
+	GetFormat() Format
+	// GetNumSamples returns the total number of sample frames.
+	GetNumSamples() uint32
+	// GetNumSamplesLeft returns the number of sample frames not yet read.
+	GetNumSamplesLeft() uint32
+	// GetSamples reads the next numSamples sample frames.
+	GetSamples(numSamples int) ([]Sample, error)
+	// ReadSamples reads the next sample frames into a caller-provided buffer.
+	ReadSamples(dst []Sample) (int, error)
+	// ReadInt16 reads the next sample frames as interleaved 16-bit samples.
+	ReadInt16(dst []int16) (int, error)
+	// ReadInt32 reads the next sample frames as interleaved 32-bit samples.
+	ReadInt32(dst []int32) (int, error)
+	// ReadFloat32 reads the next sample frames as interleaved float samples.
+	ReadFloat32(dst []float32) (int, error)
+	// Close releases the resources held by the reader.
+	Close() error
+}
+
+// Open opens the audio file at path, detects its container from the magic
+// bytes and returns a loaded reader. Containers that are recognised but
+// cannot be decoded are reported with ErrUnsupportedContainer.
+func Open(path string) (AudioReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := openReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.f = f
+	return r, nil
+}
+
+// OpenReader behaves like Open but reads the audio from ra. Closing the
+// returned reader does not close ra.
+func OpenReader(ra io.ReaderAt) (AudioReader, error) {
+	return openReader(ra)
+}
+
+func openReader(ra io.ReaderAt) (*Reader, error) {
+	c, err := DetectContainer(ra)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Supported() {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContainer, c)
+	}
+	r := &Reader{src: ra}
+	if err := r.Load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,104 @@
+package wavgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectContainer(t *testing.T) {
+	w64 := append(append([]byte{}, w64RIFFGUID...), make([]byte, 8)...)
+	w64 = append(w64, w64WAVEGUID...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want Container
+	}{
+		{"WAVE", buildWAV(), ContainerWAVE},
+		{"RIFX", buildRIFF("RIFX", "WAVE"), ContainerRIFX},
+		{"RF64", buildRIFF("RF64", "WAVE"), ContainerRF64},
+		{"BW64", buildRIFF("BW64", "WAVE"), ContainerBW64},
+		{"W64", w64, ContainerW64},
+		{"AIFF", []byte("FORM\x00\x00\x00\x04AIFF"), ContainerAIFF},
+		{"AIFC", []byte("FORM\x00\x00\x00\x04AIFC"), ContainerAIFC},
+		{"AU", []byte(".snd\x00\x00\x00\x18"), ContainerAU},
+		{"CAF", []byte("caff\x00\x01\x00\x00"), ContainerCAF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := DetectContainer(bytes.NewReader(tt.data))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, c)
+		})
+	}
+}
+
+func TestDetectContainerRejectsNonWAVE(t *testing.T) {
+	for _, form := range []string{"AVI ", "WEBP"} {
+		_, err := DetectContainer(bytes.NewReader(buildRIFF("RIFF", form)))
+		require.True(t, errors.Is(err, ErrNotWAVE), form)
+		require.Contains(t, err.Error(), form)
+	}
+}
+
+func TestDetectContainerUnknown(t *testing.T) {
+	_, err := DetectContainer(bytes.NewReader([]byte("OggS\x00\x02")))
+	require.True(t, errors.Is(err, ErrUnknownContainer))
+
+	_, err = DetectContainer(bytes.NewReader([]byte{}))
+	require.True(t, errors.Is(err, ErrUnknownContainer))
+}
+
+func TestOpen(t *testing.T) {
+	r, err := Open("testdata/read_test.wav")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Close())
+	}()
+
+	require.Equal(t, uint16(2), r.GetFormat().NumChannels)
+	samples, err := r.GetSamples(2)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{1, 2}, {3, 4}}, samples)
+}
+
+func TestOpenUnsupportedContainer(t *testing.T) {
+	_, err := OpenReader(bytes.NewReader([]byte("FORM\x00\x00\x00\x04AIFF")))
+	require.True(t, errors.Is(err, ErrUnsupportedContainer))
+	require.Contains(t, err.Error(), "AIFF")
+}
+
+func TestOpenReaderRF64(t *testing.T) {
+	data := []byte{0x01, 0x00, 0x02, 0x00, 0x03, 0x00, 0x04, 0x00}
+	ds64 := &bytes.Buffer{}
+	binary.Write(ds64, binary.LittleEndian, uint64(4+8+28+8+16+8+len(data))) // RIFF size
+	binary.Write(ds64, binary.LittleEndian, uint64(len(data)))               // data size
+	binary.Write(ds64, binary.LittleEndian, uint64(2))                       // sample count
+	binary.Write(ds64, binary.LittleEndian, uint32(0))                       // table length
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("RF64")
+	binary.Write(buf, binary.LittleEndian, uint32(0xFFFFFFFF))
+	buf.WriteString("WAVE")
+	buf.WriteString("ds64")
+	binary.Write(buf, binary.LittleEndian, uint32(ds64.Len()))
+	buf.Write(ds64.Bytes())
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	buf.Write(pcm16FmtData(2, 48000))
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(0xFFFFFFFF))
+	buf.Write(data)
+
+	r, err := OpenReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), r.GetNumSamples())
+	samples, err := r.GetSamples(2)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{1, 2}, {3, 4}}, samples)
+	require.NoError(t, r.Close())
+}
@@ -0,0 +1,144 @@
+package wavgo
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/takurooo/wavgo/internal/riff"
+)
+
+const (
+	// CUEChunkID is the ID of the cue points chunk.
+	CUEChunkID = "cue "
+	// ADTLListType is the list type of the associated data list holding
+	// cue point labels, notes and regions.
+	ADTLListType = "adtl"
+)
+
+// RegionPurpose is the ltxt purpose ID conventionally used for regions.
+const RegionPurpose = "rgn "
+
+// CuePoint is a marker or, when Length is non-zero, a region in the audio data.
+type CuePoint struct {
+	// ID uniquely identifies the cue point within the file.
+	ID uint32
+
+	// Position is the offset of the cue point in sample frames.
+	Position uint32
+
+	// Label is the name of the cue point (labl).
+	Label string
+
+	// Note is a comment attached to the cue point (note).
+	Note string
+
+	// Length is the length of a region in sample frames (ltxt). Zero means
+	// the cue point is a marker.
+	Length uint32
+
+	// Purpose is the ltxt purpose ID. It defaults to RegionPurpose for regions.
+	Purpose string
+
+	// Text is the text attached to the region (ltxt).
+	Text string
+}
+
+// parseCuePoints decodes a cue chunk and merges the labels, notes and
+// regions found in the adtl list.
+func parseCuePoints(cueData []byte, adtl []*riff.Chunk) ([]CuePoint, error) {
+	if len(cueData) < 4 {
+		return nil, invalidChunk(CUEChunkID, "too short")
+	}
+	le := binary.LittleEndian
+	numCuePoints := le.Uint32(cueData[0:4])
+	if uint64(numCuePoints)*24 > uint64(len(cueData)-4) {
+		return nil, invalidChunk(CUEChunkID, "too many cue points")
+	}
+
+	cuePoints := make([]CuePoint, numCuePoints)
+	index := make(map[uint32]int, numCuePoints)
+	for i := range cuePoints {
+		p := cueData[4+i*24:]
+		cuePoints[i] = CuePoint{
+			ID:       le.Uint32(p[0:4]),
+			Position: le.Uint32(p[20:24]),
+		}
+		index[cuePoints[i].ID] = i
+	}
+
+	for _, c := range adtl {
+		if len(c.Data) < 4 {
+			return nil, invalidChunkAt(c.ID, c.Offset, "too short")
+		}
+		i, ok := index[le.Uint32(c.Data[0:4])]
+		if !ok {
+			continue
+		}
+		switch c.ID {
+		case "labl":
+			cuePoints[i].Label = string(bytes.TrimRight(c.Data[4:], "\x00"))
+		case "note":
+			cuePoints[i].Note = string(bytes.TrimRight(c.Data[4:], "\x00"))
+		case "ltxt":
+			if len(c.Data) < 20 {
+				return nil, invalidChunkAt(c.ID, c.Offset, "too short")
+			}
+			cuePoints[i].Length = le.Uint32(c.Data[4:8])
+			cuePoints[i].Purpose = string(c.Data[8:12])
+			cuePoints[i].Text = string(bytes.TrimRight(c.Data[20:], "\x00"))
+		}
+	}
+	return cuePoints, nil
+}
+
+// encodeCuePoints returns the payloads of the cue chunk and the adtl LIST
+// chunk. The adtl payload is nil if no cue point has associated data.
+func encodeCuePoints(cuePoints []CuePoint) (cueData, adtlData []byte) {
+	le := binary.LittleEndian
+	cueData = make([]byte, 4+24*len(cuePoints))
+	le.PutUint32(cueData[0:4], uint32(len(cuePoints)))
+
+	adtl := make([]*riff.Chunk, 0)
+	addText := func(id string, cueID uint32, text string) {
+		data := make([]byte, 4, 4+len(text)+1)
+		le.PutUint32(data, cueID)
+		data = append(append(data, text...), 0)
+		adtl = append(adtl, &riff.Chunk{ID: id, Size: uint32(len(data)), Data: data})
+	}
+
+	for i, cp := range cuePoints {
+		p := cueData[4+i*24:]
+		le.PutUint32(p[0:4], cp.ID)
+		le.PutUint32(p[4:8], cp.Position)
+		copy(p[8:12], riff.DATAChunkID)
+		// p[12:20] holds dwChunkStart and dwBlockStart, which are zero
+		// for files with a single data chunk.
+		le.PutUint32(p[20:24], cp.Position)
+
+		if cp.Label != "" {
+			addText("labl", cp.ID, cp.Label)
+		}
+		if cp.Note != "" {
+			addText("note", cp.ID, cp.Note)
+		}
+		if cp.Length != 0 || cp.Text != "" {
+			purpose := cp.Purpose
+			if purpose == "" {
+				purpose = RegionPurpose
+			}
+			data := make([]byte, 20, 20+len(cp.Text)+1)
+			le.PutUint32(data[0:4], cp.ID)
+			le.PutUint32(data[4:8], cp.Length)
+			copy(data[8:12], purpose)
+			// data[12:20] holds country, language, dialect and code page.
+			if cp.Text != "" {
+				data = append(append(data, cp.Text...), 0)
+			}
+			adtl = append(adtl, &riff.Chunk{ID: "ltxt", Size: uint32(len(data)), Data: data})
+		}
+	}
+	if len(adtl) == 0 {
+		return cueData, nil
+	}
+	return cueData, riff.EncodeList(ADTLListType, adtl)
+}
@@ -0,0 +1,70 @@
+package wavgo
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/takurooo/wavgo/internal/riff"
+)
+
+func TestCuePointsRoundTrip(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    8000,
+		ByteRate:      16000,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+	cuePoints := []CuePoint{
+		{ID: 1, Position: 0, Label: "Intro"},
+		{ID: 2, Position: 2, Label: "Verse", Note: "check levels"},
+		{ID: 3, Position: 4, Length: 3, Purpose: RegionPurpose, Text: "Chorus"},
+	}
+
+	filename := "testdata/TestCuePointsRoundTrip.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetCuePoints(cuePoints)
+	require.NoError(t, w.WriteSamples(make([]Sample, 8)))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, cuePoints, r.GetCuePoints())
+	require.Equal(t, uint32(8), r.GetNumSamples())
+}
+
+func TestCuePointsEncode(t *testing.T) {
+	cueData, adtlData := encodeCuePoints([]CuePoint{{ID: 7, Position: 100}})
+	require.Nil(t, adtlData)
+	require.Len(t, cueData, 4+24)
+	require.Equal(t, uint32(1), binary.LittleEndian.Uint32(cueData[0:4]))
+	require.Equal(t, []byte("data"), cueData[12:16])
+	require.Equal(t, uint32(100), binary.LittleEndian.Uint32(cueData[24:28]))
+
+	_, adtlData = encodeCuePoints([]CuePoint{{ID: 7, Length: 10}})
+	listType, chunks, err := riff.ParseList(adtlData)
+	require.NoError(t, err)
+	require.Equal(t, ADTLListType, listType)
+	require.Len(t, chunks, 1)
+	require.Equal(t, "ltxt", chunks[0].ID)
+	require.Equal(t, []byte(RegionPurpose), chunks[0].Data[8:12])
+}
+
+func TestCuePointsInvalid(t *testing.T) {
+	_, err := parseCuePoints([]byte{0x01}, nil)
+	require.EqualError(t, err, "invalid cue chunk: too short")
+
+	_, err = parseCuePoints([]byte{0x02, 0x00, 0x00, 0x00}, nil)
+	require.EqualError(t, err, "invalid cue chunk: too many cue points")
+
+	cueData, _ := encodeCuePoints([]CuePoint{{ID: 1}})
+	_, err = parseCuePoints(cueData, []*riff.Chunk{{ID: "ltxt", Size: 4, Data: []byte{0x01, 0x00, 0x00, 0x00}, Offset: 48}})
+	require.EqualError(t, err, "invalid ltxt chunk: too short at offset 48")
+}
@@ -0,0 +1,257 @@
+package wavgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/takurooo/wavgo/internal/binio"
+	"github.com/takurooo/wavgo/internal/riff"
+)
+
+// IDs of the chunks that hold reusable free space.
+const (
+	JUNKChunkID = "JUNK"
+	PADChunkID  = "PAD "
+)
+
+// Editor modifies the metadata chunks of an existing RIFF/WAVE file in place.
+// Sample data is never read or rewritten, so editing the metadata of a large
+// file only touches the bytes of the changed chunks.
+//
+// A chunk is replaced where it is if the new data fits into its space plus
+// any JUNK or PAD chunks directly following it. Otherwise it is written into
+// the first JUNK or PAD space that is large enough, and as a last resort it
+// is appended to the end of the file and the RIFF size is updated. The space
+// left by a moved or shrunk chunk is turned into a JUNK chunk.
+//
+// Every change is written to the file immediately.
+type Editor struct {
+	f        *os.File
+	bw       *binio.Writer
+	riffSize uint32
+	chunks   []*riff.Chunk
+}
+
+// OpenEditor opens the WAV file at filePath for in-place metadata editing.
+func OpenEditor(filePath string) (*Editor, error) {
+	f, err := os.OpenFile(filePath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	e := &Editor{f: f, bw: binio.NewWriter(f)}
+	if err := e.scan(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// scan reads the chunk headers of the file.
+func (e *Editor) scan() error {
+	rc, err := riff.ScanRIFFChunk(e.f)
+	if err != nil {
+		return err
+	}
+	if rc.ID != riff.RIFFChunkID {
+		return fmt.Errorf("%w: in-place editing of %s files", ErrUnsupportedContainer, rc.ID)
+	}
+	e.riffSize = rc.Size
+	e.chunks = rc.SubChunks
+	return nil
+}
+
+// Close syncs the changes to disk and closes the file.
+func (e *Editor) Close() error {
+	if err := e.f.Sync(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
+
+// GetChunks returns every chunk of the file in file order.
+func (e *Editor) GetChunks() []*Chunk {
+	chunks := make([]*Chunk, len(e.chunks))
+	for i, c := range e.chunks {
+		chunks[i] = &Chunk{ID: c.ID, Size: c.Size, Offset: c.Offset, length: int64(c.Size), src: e.f}
+	}
+	return chunks
+}
+
+// SetInfo replaces the LIST/INFO chunk of the file, or adds one.
+func (e *Editor) SetInfo(info *Info) error {
+	data, err := encodeInfo(info)
+	if err != nil {
+		return err
+	}
+	return e.SetChunk(riff.LISTChunkID, data)
+}
+
+// SetBroadcastExtension replaces the bext chunk of the file, or adds one.
+func (e *Editor) SetBroadcastExtension(bext *BroadcastExtension) error {
+	return e.SetChunk(BEXTChunkID, encodeBroadcastExtension(bext))
+}
+
+// SetCart replaces the cart chunk of the file, or adds one.
+func (e *Editor) SetCart(cart *Cart) error {
+	return e.SetChunk(CARTChunkID, encodeCart(cart))
+}
+
+// SetIXML replaces the iXML chunk of the file, or adds one.
+func (e *Editor) SetIXML(ixml *IXML) error {
+	doc, err := ixml.Marshal()
+	if err != nil {
+		return err
+	}
+	return e.SetChunk(IXMLChunkID, doc)
+}
+
+// SetChunk replaces the first chunk with the given ID, or adds one if the
+// file has none. LIST chunks are matched by their list type, which is the
+// first four bytes of data. The fmt and data chunks cannot be replaced.
+func (e *Editor) SetChunk(id string, data []byte) error {
+	if err := validateChunkID(id); err != nil {
+		return err
+	}
+	if id == riff.LISTChunkID && len(data) < 4 {
+		return invalidChunk(riff.LISTChunkID, "too short")
+	}
+	old, err := e.find(id, data)
+	if err != nil {
+		return err
+	}
+	need := chunkSpan(uint32(len(data)))
+
+	// Replace the chunk where it is if it fits.
+	if old >= 0 {
+		if span := e.freeSpan(old, true); fitsSpan(need, span) {
+			return e.writeAt(e.chunks[old].Offset, span, id, data)
+		}
+	}
+	// Move it into free space.
+	for i, c := range e.chunks {
+		if !isFreeChunk(c.ID) {
+			continue
+		}
+		if span := e.freeSpan(i, false); fitsSpan(need, span) {
+			if old >= 0 {
+				e.markFree(old)
+			}
+			return e.writeAt(c.Offset, span, id, data)
+		}
+	}
+	// Append it to the end of the file.
+	end := 8 + int64(e.riffSize)
+	if end%2 == 1 {
+		e.bw.SetOffset(end)
+		e.bw.WriteU8(0)
+		end++
+	}
+	if end+need-8 > 0xFFFFFFFF {
+		return ErrFileTooLarge
+	}
+	if old >= 0 {
+		e.markFree(old)
+	}
+	e.bw.SetOffset(end)
+	writeChunkTo(e.bw, id, data)
+	e.bw.SetOffset(4)
+	e.bw.WriteU32(uint32(end+need-8), binary.LittleEndian)
+	if e.bw.Err() != nil {
+		return e.bw.Err()
+	}
+	return e.scan()
+}
+
+// RemoveChunk turns the first chunk with the given ID into a JUNK chunk so
+// that its space can be reused. The fmt and data chunks cannot be removed.
+func (e *Editor) RemoveChunk(id string) error {
+	if err := validateChunkID(id); err != nil {
+		return err
+	}
+	for i, c := range e.chunks {
+		if c.ID == id {
+			e.markFree(i)
+			if e.bw.Err() != nil {
+				return e.bw.Err()
+			}
+			return e.scan()
+		}
+	}
+	return riff.NewParseError(ErrChunkNotFound, id, -1, fmt.Sprintf("chunk %q not found", id))
+}
+
+// find returns the index of the first chunk matching id, or -1.
+func (e *Editor) find(id string, data []byte) (int, error) {
+	for i, c := range e.chunks {
+		if c.ID != id {
+			continue
+		}
+		if id == riff.LISTChunkID {
+			listType := make([]byte, 4)
+			if _, err := e.f.ReadAt(listType, c.Offset+8); err != nil && err != io.EOF {
+				return -1, err
+			}
+			if string(listType) != string(data[0:4]) {
+				continue
+			}
+		}
+		return i, nil
+	}
+	return -1, nil
+}
+
+// freeSpan returns the number of bytes available at chunk i: its own space
+// if includeSelf is true or it is a free chunk, plus the space of any free
+// chunks directly following it.
+func (e *Editor) freeSpan(i int, includeSelf bool) int64 {
+	span := int64(0)
+	for j := i; j < len(e.chunks); j++ {
+		c := e.chunks[j]
+		if !(j == i && includeSelf) && !isFreeChunk(c.ID) {
+			break
+		}
+		if j > i && c.Offset != e.chunks[i].Offset+span {
+			break
+		}
+		span += chunkSpan(c.Size)
+	}
+	return span
+}
+
+// writeAt writes a chunk at off and fills the rest of span with a JUNK chunk.
+func (e *Editor) writeAt(off, span int64, id string, data []byte) error {
+	e.bw.SetOffset(off)
+	writeChunkTo(e.bw, id, data)
+	if rest := span - chunkSpan(uint32(len(data))); rest > 0 {
+		writeChunkTo(e.bw, JUNKChunkID, make([]byte, rest-8))
+	}
+	if e.bw.Err() != nil {
+		return e.bw.Err()
+	}
+	return e.scan()
+}
+
+// markFree turns chunk i into a JUNK chunk by rewriting its ID.
+func (e *Editor) markFree(i int) {
+	e.bw.SetOffset(e.chunks[i].Offset)
+	e.bw.WriteS32(JUNKChunkID, binary.BigEndian)
+}
+
+func isFreeChunk(id string) bool {
+	return id == JUNKChunkID || id == PADChunkID
+}
+
+// chunkSpan returns the number of bytes a chunk with size bytes of data
+// occupies in the file, including the header and pad byte.
+func chunkSpan(size uint32) int64 {
+	return 8 + int64(size) + int64(size&1)
+}
+
+// fitsSpan reports whether a chunk occupying need bytes fits into span bytes,
+// leaving either no space or enough for a JUNK chunk header.
+func fitsSpan(need, span int64) bool {
+	return need == span || span-need >= 8
+}
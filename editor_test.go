@@ -0,0 +1,158 @@
+package wavgo
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeEditorTestFile writes a small 16-bit mono file with the given chunks
+// added before and after the data chunk.
+func writeEditorTestFile(t *testing.T, filename string, info *Info, before, after []testChunk) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    8000,
+		ByteRate:      16000,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	if info != nil {
+		w.SetInfo(info)
+	}
+	for _, c := range before {
+		require.NoError(t, w.AddChunk(c.id, c.data, BeforeData))
+	}
+	for _, c := range after {
+		require.NoError(t, w.AddChunk(c.id, c.data, AfterData))
+	}
+	require.NoError(t, w.WriteSamples([]Sample{{1, 0}, {-2, 0}, {3, 0}, {-4, 0}}))
+	require.NoError(t, w.Close())
+}
+
+// chunkLayout returns the IDs and data chunk bytes of the file.
+func chunkLayout(t *testing.T, filename string) ([]string, []byte, *Reader) {
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	t.Cleanup(func() { r.Close() })
+	require.NoError(t, r.Load())
+	var ids []string
+	var data []byte
+	for _, c := range r.GetChunks() {
+		ids = append(ids, c.ID)
+		if c.ID == "data" {
+			b, err := c.Data()
+			require.NoError(t, err)
+			data = b
+		}
+	}
+	return ids, data, r
+}
+
+func TestEditorReplaceInPlace(t *testing.T) {
+	filename := "testdata/TestEditorReplaceInPlace.wav"
+	defer os.Remove(filename)
+	writeEditorTestFile(t, filename, &Info{Title: "A"}, []testChunk{{"JUNK", make([]byte, 64)}}, nil)
+	_, wantData, _ := chunkLayout(t, filename)
+	before, err := os.Stat(filename)
+	require.NoError(t, err)
+
+	e, err := OpenEditor(filename)
+	require.NoError(t, err)
+	require.NoError(t, e.SetInfo(&Info{Title: "A much longer title", Artist: "Someone"}))
+	require.NoError(t, e.Close())
+
+	after, err := os.Stat(filename)
+	require.NoError(t, err)
+	require.Equal(t, before.Size(), after.Size())
+
+	ids, data, r := chunkLayout(t, filename)
+	require.Equal(t, []string{"fmt ", "LIST", "JUNK", "data"}, ids)
+	require.Equal(t, wantData, data)
+	require.Equal(t, &Info{Title: "A much longer title", Artist: "Someone"}, r.GetInfo())
+}
+
+func TestEditorShrink(t *testing.T) {
+	filename := "testdata/TestEditorShrink.wav"
+	defer os.Remove(filename)
+	writeEditorTestFile(t, filename, &Info{Title: strings.Repeat("t", 40)}, nil, nil)
+
+	e, err := OpenEditor(filename)
+	require.NoError(t, err)
+	require.NoError(t, e.SetInfo(&Info{Title: "short"}))
+	require.NoError(t, e.Close())
+
+	ids, _, r := chunkLayout(t, filename)
+	require.Equal(t, []string{"fmt ", "LIST", "JUNK", "data"}, ids)
+	require.Equal(t, &Info{Title: "short"}, r.GetInfo())
+}
+
+func TestEditorMoveIntoFreeSpace(t *testing.T) {
+	filename := "testdata/TestEditorMoveIntoFreeSpace.wav"
+	defer os.Remove(filename)
+	writeEditorTestFile(t, filename, nil, nil, []testChunk{{"JUNK", make([]byte, 1024)}})
+	_, wantData, _ := chunkLayout(t, filename)
+	before, err := os.Stat(filename)
+	require.NoError(t, err)
+
+	bext := &BroadcastExtension{Description: "edited in place", CodingHistory: "A=PCM\r\n;"}
+	e, err := OpenEditor(filename)
+	require.NoError(t, err)
+	require.NoError(t, e.SetBroadcastExtension(bext))
+	require.NoError(t, e.Close())
+
+	after, err := os.Stat(filename)
+	require.NoError(t, err)
+	require.Equal(t, before.Size(), after.Size())
+
+	ids, data, r := chunkLayout(t, filename)
+	require.Equal(t, []string{"fmt ", "data", "bext", "JUNK"}, ids)
+	require.Equal(t, wantData, data)
+	require.Equal(t, bext, r.GetBroadcastExtension())
+}
+
+func TestEditorAppend(t *testing.T) {
+	filename := "testdata/TestEditorAppend.wav"
+	defer os.Remove(filename)
+	writeEditorTestFile(t, filename, &Info{Title: "A"}, nil, nil)
+	_, wantData, _ := chunkLayout(t, filename)
+
+	e, err := OpenEditor(filename)
+	require.NoError(t, err)
+	require.NoError(t, e.SetInfo(&Info{Title: "Title that no longer fits"}))
+	require.NoError(t, e.SetChunk("xprv", []byte("private!")))
+	require.NoError(t, e.Close())
+
+	ids, data, r := chunkLayout(t, filename)
+	require.Equal(t, []string{"fmt ", "JUNK", "data", "LIST", "xprv"}, ids)
+	require.Equal(t, wantData, data)
+	require.Equal(t, &Info{Title: "Title that no longer fits"}, r.GetInfo())
+	samples, err := r.GetSamples(4)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{1, 0}, {-2, 0}, {3, 0}, {-4, 0}}, samples)
+}
+
+func TestEditorRemoveChunk(t *testing.T) {
+	filename := "testdata/TestEditorRemoveChunk.wav"
+	defer os.Remove(filename)
+	writeEditorTestFile(t, filename, nil, []testChunk{{"xprv", []byte("secret")}}, nil)
+
+	e, err := OpenEditor(filename)
+	require.NoError(t, err)
+	require.NoError(t, e.RemoveChunk("xprv"))
+	require.EqualError(t, e.RemoveChunk("xprv"), `chunk "xprv" not found`)
+	require.Error(t, e.SetChunk("data", nil))
+	require.NoError(t, e.Close())
+
+	ids, _, _ := chunkLayout(t, filename)
+	require.Equal(t, []string{"fmt ", "JUNK", "data"}, ids)
+}
+
+func TestOpenEditorError(t *testing.T) {
+	_, err := OpenEditor("non/existent/file.wav")
+	require.Error(t, err)
+}
@@ -1,6 +1,109 @@
 package wavgo
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/takurooo/wavgo/internal/binio"
+	"github.com/takurooo/wavgo/internal/riff"
+)
 
 // ErrUnsupportedBitsPerSample is returned when the number of bits per sample is not supported.
 var ErrUnsupportedBitsPerSample = errors.New("unsupported BitsPerSample")
+
+// ErrUnknownContainer is returned when the magic bytes of a file do not match any known audio container.
+var ErrUnknownContainer = errors.New("unknown audio container")
+
+// ErrUnsupportedContainer is returned when the container is recognised but cannot be decoded.
+var ErrUnsupportedContainer = errors.New("unsupported audio container")
+
+// ErrNotWAVE is returned when a RIFF-style file carries a form type other than WAVE, such as AVI or WebP.
+var ErrNotWAVE = riff.ErrNotWAVE
+
+// ErrNotRIFF is returned when a file does not start with a RIFF, RF64 or BW64 header.
+var ErrNotRIFF = riff.ErrNotRIFF
+
+// ErrChunkNotFound is returned when a required chunk, such as fmt or data, is missing.
+var ErrChunkNotFound = riff.ErrChunkNotFound
+
+// ErrInvalidChunkSize is returned when a chunk size exceeds the bytes left in its parent.
+var ErrInvalidChunkSize = riff.ErrInvalidChunkSize
+
+// ErrInvalidChunk is returned when the data of a chunk is malformed.
+var ErrInvalidChunk = riff.ErrInvalidChunk
+
+// ErrTruncated is returned when a file ends in the middle of a chunk.
+var ErrTruncated = riff.ErrTruncated
+
+// ErrNotOpen is returned when a Reader is loaded before it was opened.
+var ErrNotOpen = errors.New("reader is not open")
+
+// ErrNotEnoughSamples is returned when more samples are requested than are left to read.
+var ErrNotEnoughSamples = errors.New("requested samples exceed remaining samples")
+
+// ErrInvalidChunkID is returned for a chunk ID that cannot be written.
+var ErrInvalidChunkID = errors.New("invalid chunk ID")
+
+// ErrHeaderWritten is returned when a chunk is added before the data chunk
+// after the Writer wrote the header.
+var ErrHeaderWritten = errors.New("header already written")
+
+// ErrFileTooLarge is returned when a file would exceed the 4 GiB size limit of RIFF.
+var ErrFileTooLarge = errors.New("file exceeds the 4 GiB RIFF size limit")
+
+// ErrInvalidFourCC is returned when a four-character code, such as a chunk
+// ID or a form type, does not have exactly 4 bytes.
+var ErrInvalidFourCC = binio.ErrInvalidFourCC
+
+// ErrNegativeSampleCount is returned when a negative number of samples is requested.
+var ErrNegativeSampleCount = errors.New("numSamples cannot be negative")
+
+// ErrTooManyChannels is returned by the Sample based methods for a format with
+// more channels than a Sample holds. The interleaved methods support any
+// number of channels.
+var ErrTooManyChannels = errors.New("too many channels for Sample")
+
+// ErrPartialFrame is returned when an interleaved buffer does not hold a whole number of sample frames.
+var ErrPartialFrame = errors.New("interleaved buffer does not hold whole sample frames")
+
+// ErrInvalidTemplate is returned when a RollingOptions.Template contains neither {seq} nor {time}.
+var ErrInvalidTemplate = errors.New("rolling template must contain {seq} or {time}")
+
+// ErrLimitTooSmall is returned when the RollingOptions limits leave no room for a sample frame.
+var ErrLimitTooSmall = errors.New("rolling limits leave no room for a sample frame")
+
+// ParseError describes why a chunk of a file could not be parsed: the chunk
+// ID, the offset of the chunk in the file and the reason. It wraps one of the
+// sentinel errors ErrNotRIFF, ErrNotWAVE, ErrChunkNotFound,
+// ErrInvalidChunkSize, ErrInvalidChunk or ErrTruncated.
+type ParseError = riff.ParseError
+
+// ErrInvalidFormat is wrapped by every *FormatError.
+var ErrInvalidFormat = errors.New("invalid format")
+
+// FormatError reports a Format field that is missing or inconsistent with the other fields.
+type FormatError struct {
+	Field  string // name of the Format field, e.g. "ByteRate"
+	Reason string
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+func (e *FormatError) Unwrap() error {
+	return ErrInvalidFormat
+}
+
+// invalidChunk returns a *ParseError for malformed chunk data. The Reader
+// fills in the offset of the chunk.
+func invalidChunk(id, reason string) error {
+	return invalidChunkAt(id, -1, reason)
+}
+
+// invalidChunkAt is like invalidChunk for a chunk whose offset is known, such
+// as a sub-chunk of a LIST chunk.
+func invalidChunkAt(id string, offset int64, reason string) error {
+	return riff.NewParseError(ErrInvalidChunk, id, offset, fmt.Sprintf("invalid %s chunk: %s", strings.TrimSpace(id), reason))
+}
@@ -0,0 +1,325 @@
+package wavgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// ID3 tags are stored in WAV files as a chunk whose ID is either "id3 " or "ID3 ".
+const (
+	ID3ChunkID      = "id3 "
+	ID3ChunkIDUpper = "ID3 "
+)
+
+// ID3 text encodings.
+const (
+	id3EncodingLatin1  = 0
+	id3EncodingUTF16   = 1
+	id3EncodingUTF16BE = 2
+	id3EncodingUTF8    = 3
+)
+
+// ID3Tag holds the text frames, comment and attached pictures of an
+// ID3v2.3 or ID3v2.4 tag.
+type ID3Tag struct {
+	// Version is the major version of the tag, 3 or 4. Zero is written as 4.
+	Version uint8
+
+	// Text holds the text information frames (T*** except TXXX) keyed by
+	// frame ID, e.g. "TIT2" for the title. Multiple values are joined with "/".
+	Text map[string]string
+
+	// Comment is the text of the first COMM frame.
+	Comment string
+
+	// Pictures holds the attached pictures (APIC).
+	Pictures []Picture
+}
+
+// Picture is an attached picture such as album artwork.
+type Picture struct {
+	MIMEType    string // e.g. "image/jpeg"
+	Type        uint8  // ID3 picture type, 3 is the front cover
+	Description string
+	Data        []byte
+}
+
+// id3InfoFields maps ID3 text frames to the Info fields they populate.
+var id3InfoFields = []struct {
+	frameID string
+	infoID  string
+}{
+	{"TIT2", "INAM"},
+	{"TPE1", "IART"},
+	{"TALB", "IPRD"},
+	{"TRCK", "ITRK"},
+	{"TSSE", "ISFT"},
+	{"TDRC", "ICRD"},
+	{"TYER", "ICRD"},
+	{"TCON", "IGNR"},
+	{"TCOP", "ICOP"},
+	{"TENC", "ITCH"},
+}
+
+// mergeInto fills the empty fields of info from the tag. Fields already set
+// in info, which come from the LIST/INFO chunk, take precedence.
+func (t *ID3Tag) mergeInto(info *Info) {
+	fields := info.fields()
+	for _, m := range id3InfoFields {
+		value := t.Text[m.frameID]
+		if value == "" {
+			continue
+		}
+		for _, f := range fields {
+			if f.id == m.infoID && *f.value == "" {
+				*f.value = value
+			}
+		}
+	}
+	if info.Comment == "" {
+		info.Comment = t.Comment
+	}
+	if len(info.Pictures) == 0 {
+		info.Pictures = t.Pictures
+	}
+}
+
+// parseID3 decodes an ID3v2.3 or ID3v2.4 tag.
+func parseID3(data []byte) (*ID3Tag, error) {
+	if len(data) < 10 || string(data[0:3]) != "ID3" {
+		return nil, invalidID3("invalid ID3 tag: missing header")
+	}
+	version, flags := data[3], data[5]
+	if version != 3 && version != 4 {
+		return nil, invalidID3("unsupported ID3 version")
+	}
+	size := int(decodeSynchsafe(data[6:10]))
+	if size > len(data)-10 {
+		return nil, invalidID3("invalid ID3 tag: size exceeds chunk")
+	}
+	body := data[10 : 10+size]
+	if version == 3 && flags&0x80 != 0 {
+		body = removeUnsynchronisation(body)
+	}
+	if flags&0x40 != 0 {
+		if len(body) < 4 {
+			return nil, invalidID3("invalid ID3 tag: truncated extended header")
+		}
+		extSize := int(binary.BigEndian.Uint32(body[0:4])) + 4
+		if version == 4 {
+			extSize = int(decodeSynchsafe(body[0:4]))
+		}
+		if extSize > len(body) {
+			return nil, invalidID3("invalid ID3 tag: truncated extended header")
+		}
+		body = body[extSize:]
+	}
+
+	tag := &ID3Tag{Version: version, Text: make(map[string]string)}
+	hasComment := false
+	for len(body) >= 10 && body[0] != 0 {
+		id := string(body[0:4])
+		frameSize := int(binary.BigEndian.Uint32(body[4:8]))
+		if version == 4 {
+			frameSize = int(decodeSynchsafe(body[4:8]))
+		}
+		frameFlags := body[9]
+		if frameSize > len(body)-10 {
+			return nil, invalidID3("invalid ID3 frame size: exceeds tag size")
+		}
+		frame := body[10 : 10+frameSize]
+		body = body[10+frameSize:]
+
+		if version == 3 && frameFlags&0xC0 != 0 || version == 4 && frameFlags&0x0C != 0 {
+			continue // compressed or encrypted frames are not supported
+		}
+		if version == 4 && frameFlags&0x02 != 0 {
+			frame = removeUnsynchronisation(frame)
+		}
+		if version == 4 && frameFlags&0x01 != 0 {
+			if len(frame) < 4 {
+				continue
+			}
+			frame = frame[4:] // data length indicator
+		}
+		if len(frame) == 0 {
+			continue
+		}
+
+		switch {
+		case id == "COMM":
+			if hasComment || len(frame) < 4 {
+				continue
+			}
+			_, text := splitID3String(frame[0], frame[4:])
+			tag.Comment = decodeID3String(frame[0], text)
+			hasComment = true
+		case id == "APIC":
+			pic, ok := parseID3Picture(frame)
+			if ok {
+				tag.Pictures = append(tag.Pictures, pic)
+			}
+		case id[0] == 'T' && id != "TXXX":
+			text := decodeID3String(frame[0], frame[1:])
+			tag.Text[id] = strings.ReplaceAll(strings.TrimRight(text, "\x00"), "\x00", "/")
+		}
+	}
+	return tag, nil
+}
+
+func parseID3Picture(frame []byte) (Picture, bool) {
+	encoding := frame[0]
+	rest := frame[1:]
+	i := bytes.IndexByte(rest, 0)
+	if i < 0 || i+2 > len(rest) {
+		return Picture{}, false
+	}
+	pic := Picture{MIMEType: string(rest[:i]), Type: rest[i+1]}
+	desc, data := splitID3String(encoding, rest[i+2:])
+	pic.Description = decodeID3String(encoding, desc)
+	pic.Data = append([]byte(nil), data...)
+	return pic, true
+}
+
+// splitID3String splits b after the first string terminator of the given
+// encoding and returns the string without terminator and the remainder.
+func splitID3String(encoding byte, b []byte) ([]byte, []byte) {
+	if encoding == id3EncodingUTF16 || encoding == id3EncodingUTF16BE {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return b[:i], b[i+2:]
+			}
+		}
+		return b, nil
+	}
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return b[:i], b[i+1:]
+	}
+	return b, nil
+}
+
+// decodeID3String converts text in the given ID3 encoding to UTF-8.
+func decodeID3String(encoding byte, b []byte) string {
+	switch encoding {
+	case id3EncodingUTF16, id3EncodingUTF16BE:
+		var order binary.ByteOrder = binary.BigEndian
+		if encoding == id3EncodingUTF16 && len(b) >= 2 {
+			if b[0] == 0xFF && b[1] == 0xFE {
+				order = binary.LittleEndian
+				b = b[2:]
+			} else if b[0] == 0xFE && b[1] == 0xFF {
+				b = b[2:]
+			}
+		}
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			units[i] = order.Uint16(b[i*2:])
+		}
+		return string(utf16.Decode(units))
+	case id3EncodingUTF8:
+		return string(b)
+	default:
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	}
+}
+
+// encodeID3String encodes s for a frame of the given tag version: UTF-16
+// with a byte order mark for ID3v2.3 and UTF-8 for ID3v2.4.
+func encodeID3String(version uint8, s string) (byte, []byte) {
+	if version == 3 {
+		b := []byte{0xFF, 0xFE}
+		for _, u := range utf16.Encode([]rune(s)) {
+			b = binary.LittleEndian.AppendUint16(b, u)
+		}
+		return id3EncodingUTF16, b
+	}
+	return id3EncodingUTF8, []byte(s)
+}
+
+// encodeID3 returns an ID3 tag with text frames in frame ID order followed
+// by the comment and pictures.
+func encodeID3(t *ID3Tag) []byte {
+	version := t.Version
+	if version != 3 {
+		version = 4
+	}
+	terminator := func(encoding byte) []byte {
+		if encoding == id3EncodingUTF16 {
+			return []byte{0, 0}
+		}
+		return []byte{0}
+	}
+
+	body := make([]byte, 0)
+	addFrame := func(id string, frame []byte) {
+		header := make([]byte, 10)
+		copy(header[0:4], id)
+		if version == 4 {
+			copy(header[4:8], encodeSynchsafe(uint32(len(frame))))
+		} else {
+			binary.BigEndian.PutUint32(header[4:8], uint32(len(frame)))
+		}
+		body = append(append(body, header...), frame...)
+	}
+
+	ids := make([]string, 0, len(t.Text))
+	for id := range t.Text {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		encoding, text := encodeID3String(version, t.Text[id])
+		addFrame(id, append([]byte{encoding}, text...))
+	}
+	if t.Comment != "" {
+		encoding, text := encodeID3String(version, t.Comment)
+		frame := append([]byte{encoding}, "eng"...)
+		frame = append(frame, terminator(encoding)...) // empty description
+		addFrame("COMM", append(frame, text...))
+	}
+	for _, p := range t.Pictures {
+		encoding, desc := encodeID3String(version, p.Description)
+		frame := append([]byte{encoding}, p.MIMEType...)
+		frame = append(frame, 0, p.Type)
+		frame = append(append(frame, desc...), terminator(encoding)...)
+		addFrame("APIC", append(frame, p.Data...))
+	}
+
+	header := []byte{'I', 'D', '3', version, 0, 0}
+	header = append(header, encodeSynchsafe(uint32(len(body)))...)
+	return append(header, body...)
+}
+
+func decodeSynchsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
+
+func encodeSynchsafe(v uint32) []byte {
+	return []byte{byte(v>>21) & 0x7F, byte(v>>14) & 0x7F, byte(v>>7) & 0x7F, byte(v) & 0x7F}
+}
+
+// removeUnsynchronisation reverses the ID3 unsynchronisation scheme, which
+// inserts a zero byte after every 0xFF.
+func removeUnsynchronisation(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		out = append(out, b[i])
+		if b[i] == 0xFF && i+1 < len(b) && b[i+1] == 0x00 {
+			i++
+		}
+	}
+	return out
+}
+
+// invalidID3 returns a *ParseError for a malformed ID3 tag. The Reader fills
+// in the ID of the chunk, which is either "id3 " or "ID3 ", and its offset.
+func invalidID3(reason string) error {
+	return &ParseError{Offset: -1, Reason: reason, Err: ErrInvalidChunk}
+}
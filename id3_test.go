@@ -0,0 +1,100 @@
+package wavgo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/takurooo/wavgo/internal/riff"
+)
+
+func TestID3RoundTrip(t *testing.T) {
+	for _, version := range []uint8{3, 4} {
+		tag := &ID3Tag{
+			Version: version,
+			Text: map[string]string{
+				"TIT2": "Señorita",
+				"TPE1": "Artist",
+				"TALB": "Album",
+			},
+			Comment: "Mastered ♪",
+			Pictures: []Picture{
+				{MIMEType: "image/png", Type: 3, Description: "cover", Data: []byte{0x89, 'P', 'N', 'G', 0xFF, 0x00}},
+			},
+		}
+		got, err := parseID3(encodeID3(tag))
+		require.NoError(t, err)
+		require.Equal(t, tag, got)
+	}
+}
+
+func TestID3ParseLatin1AndUnsynchronisation(t *testing.T) {
+	frame := []byte{id3EncodingLatin1, 'C', 'a', 'f', 0xE9}
+	body := []byte("TIT2\x00\x00\x00\x05\x00\x00")
+	body = append(body, frame...)
+	body = append(body, 0xFF, 0x00, 0x00, 0x00) // unsynchronised 0xFF followed by padding
+	data := append([]byte{'I', 'D', '3', 3, 0, 0x80}, encodeSynchsafe(uint32(len(body)))...)
+	data = append(data, body...)
+
+	tag, err := parseID3(data)
+	require.NoError(t, err)
+	require.Equal(t, "Café", tag.Text["TIT2"])
+}
+
+func TestID3ParseInvalid(t *testing.T) {
+	_, err := parseID3([]byte("TAG"))
+	require.EqualError(t, err, "invalid ID3 tag: missing header")
+
+	_, err = parseID3([]byte{'I', 'D', '3', 2, 0, 0, 0, 0, 0, 0})
+	require.EqualError(t, err, "unsupported ID3 version")
+
+	_, err = parseID3([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 0x7F})
+	require.EqualError(t, err, "invalid ID3 tag: size exceeds chunk")
+
+	body := []byte("TIT2\x00\x00\x00\x7F\x00\x00")
+	data := append([]byte{'I', 'D', '3', 4, 0, 0}, encodeSynchsafe(uint32(len(body)))...)
+	_, err = parseID3(append(data, body...))
+	require.EqualError(t, err, "invalid ID3 frame size: exceeds tag size")
+}
+
+func TestReaderID3Precedence(t *testing.T) {
+	tag := encodeID3(&ID3Tag{
+		Text:     map[string]string{"TIT2": "ID3 title", "TPE1": "ID3 artist", "TALB": "ID3 album"},
+		Pictures: []Picture{{MIMEType: "image/jpeg", Type: 3, Data: []byte{0xFF, 0xD8}}},
+	})
+	if len(tag)%2 == 1 {
+		tag = append(tag, 0) // keep the fixture free of pad bytes
+	}
+	list := riff.EncodeList(riff.INFOListType, []*riff.Chunk{{ID: "INAM", Size: 6, Data: []byte("INFO!\x00")}})
+	data := buildWAV(
+		testChunk{"fmt ", pcm16FmtData(1, 8000)},
+		testChunk{"LIST", list},
+		testChunk{"id3 ", tag},
+		testChunk{"data", []byte{0x01, 0x00}},
+	)
+
+	r := &Reader{src: bytes.NewReader(data)}
+	require.NoError(t, r.Load())
+	info := r.GetInfo()
+	require.Equal(t, "INFO!", info.Title)
+	require.Equal(t, "ID3 artist", info.Artist)
+	require.Equal(t, "ID3 album", info.Album)
+	require.Len(t, info.Pictures, 1)
+	require.Equal(t, "ID3 title", r.GetID3().Text["TIT2"])
+}
+
+func TestReaderID3Only(t *testing.T) {
+	tag := encodeID3(&ID3Tag{Version: 3, Text: map[string]string{"TIT2": "Title"}})
+	if len(tag)%2 == 1 {
+		tag = append(tag, 0)
+	}
+	data := buildWAV(
+		testChunk{"fmt ", pcm16FmtData(1, 8000)},
+		testChunk{"ID3 ", tag},
+		testChunk{"data", []byte{0x01, 0x00}},
+	)
+
+	r := &Reader{src: bytes.NewReader(data)}
+	require.NoError(t, r.Load())
+	require.Equal(t, &Info{Title: "Title"}, r.GetInfo())
+}
@@ -0,0 +1,118 @@
+package wavgo
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/takurooo/wavgo/internal/riff"
+)
+
+// Info holds the textual metadata stored in a LIST chunk of type INFO,
+// supplemented by an ID3 tag when the file has one (see Reader.GetInfo).
+// Empty fields are omitted when the chunk is written.
+type Info struct {
+	Title        string // INAM
+	Artist       string // IART
+	Album        string // IPRD
+	TrackNumber  string // ITRK
+	Comment      string // ICMT
+	Software     string // ISFT
+	CreationDate string // ICRD
+	Genre        string // IGNR
+	Copyright    string // ICOP
+	Engineer     string // IENG
+	Technician   string // ITCH
+	Keywords     string // IKEY
+	Subject      string // ISBJ
+	Source       string // ISRC
+
+	// Other holds INFO entries without a dedicated field, keyed by chunk ID.
+	Other map[string]string
+
+	// Pictures holds artwork taken from an ID3 tag. LIST/INFO cannot store
+	// pictures, so Writer.SetInfo ignores this field; use Writer.SetID3 to
+	// write artwork.
+	Pictures []Picture
+}
+
+type infoField struct {
+	id    string
+	value *string
+}
+
+func (i *Info) fields() []infoField {
+	return []infoField{
+		{"INAM", &i.Title},
+		{"IART", &i.Artist},
+		{"IPRD", &i.Album},
+		{"ITRK", &i.TrackNumber},
+		{"ICMT", &i.Comment},
+		{"ISFT", &i.Software},
+		{"ICRD", &i.CreationDate},
+		{"IGNR", &i.Genre},
+		{"ICOP", &i.Copyright},
+		{"IENG", &i.Engineer},
+		{"ITCH", &i.Technician},
+		{"IKEY", &i.Keywords},
+		{"ISBJ", &i.Subject},
+		{"ISRC", &i.Source},
+	}
+}
+
+// parseInfo decodes the sub-chunks of a LIST/INFO chunk.
+func parseInfo(chunks []*riff.Chunk) *Info {
+	info := &Info{}
+	fields := info.fields()
+	for _, c := range chunks {
+		value := string(bytes.TrimRight(c.Data, "\x00"))
+		found := false
+		for _, f := range fields {
+			if f.id == c.ID {
+				*f.value = value
+				found = true
+				break
+			}
+		}
+		if !found {
+			if info.Other == nil {
+				info.Other = make(map[string]string)
+			}
+			info.Other[c.ID] = value
+		}
+	}
+	return info
+}
+
+// encodeInfo returns the payload of a LIST/INFO chunk. Each value is
+// NUL-terminated; EncodeList adds the pad byte after odd-sized entries.
+func encodeInfo(info *Info) ([]byte, error) {
+	chunks := make([]*riff.Chunk, 0)
+	add := func(id, value string) error {
+		if len(id) != 4 {
+			return fmt.Errorf("%w: INFO chunk IDs must be exactly 4 characters", ErrInvalidChunkID)
+		}
+		if value == "" {
+			return nil
+		}
+		data := append([]byte(value), 0)
+		chunks = append(chunks, &riff.Chunk{ID: id, Size: uint32(len(data)), Data: data})
+		return nil
+	}
+	for _, f := range info.fields() {
+		if err := add(f.id, *f.value); err != nil {
+			return nil, err
+		}
+	}
+	ids := make([]string, 0, len(info.Other))
+	for id := range info.Other {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := add(id, info.Other[id]); err != nil {
+			return nil, err
+		}
+	}
+	return riff.EncodeList(riff.INFOListType, chunks), nil
+}
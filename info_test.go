@@ -0,0 +1,78 @@
+package wavgo
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/takurooo/wavgo/internal/riff"
+)
+
+func TestInfoRoundTrip(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    44100,
+		ByteRate:      88200,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+	info := &Info{
+		Title:        "Take 1",
+		Artist:       "wavgo",
+		Comment:      "odd",
+		Software:     "wavgo test",
+		CreationDate: "2024-01-02",
+		Genre:        "Field Recording",
+		Other:        map[string]string{"IMED": "DAT"},
+	}
+
+	filename := "testdata/TestInfoRoundTrip.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetInfo(info)
+	require.NoError(t, w.WriteSamples([]Sample{{1, 0}, {2, 0}}))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, info, r.GetInfo())
+
+	samples, err := r.GetSamples(2)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{1, 0}, {2, 0}}, samples)
+}
+
+func TestInfoEncodePadding(t *testing.T) {
+	data, err := encodeInfo(&Info{Title: "ab", Artist: "abc"})
+	require.NoError(t, err)
+	// "INFO" + INAM(3 bytes + pad) + IART(4 bytes)
+	require.Equal(t, []byte("INFOINAM\x03\x00\x00\x00ab\x00\x00IART\x04\x00\x00\x00abc\x00"), data)
+
+	_, err = encodeInfo(&Info{Other: map[string]string{"TOOLONG": "x"}})
+	require.Error(t, err)
+}
+
+func TestReaderWithoutInfo(t *testing.T) {
+	r := NewReader()
+	require.NoError(t, r.Open("testdata/read_test.wav"))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Nil(t, r.GetInfo())
+}
+
+func TestReaderInfoBeforeFmt(t *testing.T) {
+	list := riff.EncodeList(riff.INFOListType, []*riff.Chunk{{ID: "INAM", Size: 6, Data: []byte("title\x00")}})
+	data := buildWAV(
+		testChunk{"LIST", list},
+		testChunk{"fmt ", pcm16FmtData(1, 8000)},
+		testChunk{"data", []byte{0x01, 0x00}},
+	)
+	r := &Reader{src: bytes.NewReader(data)}
+	require.NoError(t, r.Load())
+	require.Equal(t, &Info{Title: "title"}, r.GetInfo())
+}
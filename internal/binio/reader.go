@@ -3,38 +3,81 @@ package binio
 import (
 	"encoding/binary"
 	"io"
+	"math"
 )
 
 type Reader struct {
 	r   io.ReaderAt
 	off int64
 	err error
+	buf [4]byte // scratch space for fixed-size fields
 }
 
 func NewReader(r io.ReaderAt) *Reader {
 	return &Reader{r: r}
 }
 
-func (br *Reader) read(n int) []byte {
+// read fills b from the current offset. The returned slice aliases b and is
+// nil if an error occurred.
+func (br *Reader) read(b []byte) []byte {
 	if br.err != nil {
 		return nil
 	}
-	buf := make([]byte, n)
-	_, err := br.r.ReadAt(buf, br.off)
+	if len(b) == 0 {
+		return b
+	}
+	m, err := br.r.ReadAt(b, br.off)
+	if m == len(b) {
+		err = nil // io.ReaderAt may return io.EOF along with the last bytes
+	} else if err == io.EOF {
+		// Every read asks for a field that must be present.
+		err = io.ErrUnexpectedEOF
+	}
 	if err != nil {
 		br.err = err
 		return nil
 	}
-	br.off += int64(n)
-	return buf
+	br.off += int64(len(b))
+	return b
 }
 
+// ReadRaw reads n bytes into a newly allocated slice. The slice is only
+// allocated once the last of the n bytes is known to exist, so that a
+// corrupt size fails with io.ErrUnexpectedEOF instead of exhausting memory.
 func (br *Reader) ReadRaw(n uint64) []byte {
-	return br.read(int(n))
+	if br.err != nil {
+		return nil
+	}
+	if n > math.MaxInt64-uint64(br.off) {
+		br.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	if n > 0 {
+		if _, err := br.r.ReadAt(br.buf[:1], br.off+int64(n)-1); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			br.err = err
+			return nil
+		}
+	}
+	return br.read(make([]byte, n))
+}
+
+// ReadFull reads exactly len(b) bytes into b without allocating.
+func (br *Reader) ReadFull(b []byte) {
+	br.read(b)
+}
+
+func (br *Reader) Skip(n uint64) {
+	if br.err != nil {
+		return
+	}
+	br.off += int64(n)
 }
 
 func (br *Reader) ReadU8() uint8 {
-	b := br.read(1)
+	b := br.read(br.buf[:1])
 	if br.err != nil {
 		return 0
 	}
@@ -42,7 +85,7 @@ func (br *Reader) ReadU8() uint8 {
 }
 
 func (br *Reader) ReadU16(order binary.ByteOrder) uint16 {
-	b := br.read(2)
+	b := br.read(br.buf[:2])
 	if br.err != nil {
 		return 0
 	}
@@ -50,7 +93,7 @@ func (br *Reader) ReadU16(order binary.ByteOrder) uint16 {
 }
 
 func (br *Reader) ReadU24(order binary.ByteOrder) uint32 {
-	b := br.read(3)
+	b := br.read(br.buf[:3])
 	if br.err != nil {
 		return 0
 	}
@@ -61,7 +104,7 @@ func (br *Reader) ReadU24(order binary.ByteOrder) uint32 {
 }
 
 func (br *Reader) ReadU32(order binary.ByteOrder) uint32 {
-	b := br.read(4)
+	b := br.read(br.buf[:4])
 	if br.err != nil {
 		return 0
 	}
@@ -70,13 +113,17 @@ func (br *Reader) ReadU32(order binary.ByteOrder) uint32 {
 
 func (br *Reader) ReadS32(order binary.ByteOrder) string {
 	_ = order // order is ignored for strings
-	b := br.read(4)
+	b := br.read(br.buf[:4])
 	if br.err != nil {
 		return ""
 	}
 	return string(b)
 }
 
+func (br *Reader) GetOffset() int64 {
+	return br.off
+}
+
 func (br *Reader) Err() error {
 	return br.err
 }
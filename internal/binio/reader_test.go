@@ -116,6 +116,15 @@ func TestReaderRaw(t *testing.T) {
 	})
 }
 
+func TestReaderRawBeyondEOF(t *testing.T) {
+	for _, n := range []uint64{6, 1 << 40, 1<<64 - 1} {
+		reader := NewReader(bytes.NewReader([]byte{0x01, 0x02, 0x03, 0x04, 0x05}))
+		require.Nil(t, reader.ReadRaw(n))
+		require.ErrorIs(t, reader.Err(), io.ErrUnexpectedEOF)
+		require.Zero(t, reader.GetOffset())
+	}
+}
+
 func TestReaderErrorHandling(t *testing.T) {
 	// Test EOF error
 	data := []byte{0x01}
@@ -128,7 +137,7 @@ func TestReaderErrorHandling(t *testing.T) {
 
 	// Read beyond EOF
 	u16 := reader.ReadU16(binary.LittleEndian)
-	require.Error(t, reader.Err())
+	require.ErrorIs(t, reader.Err(), io.ErrUnexpectedEOF)
 	require.Equal(t, uint16(0), u16)
 
 	// Subsequent reads should return zero values
@@ -162,3 +171,23 @@ type failingReaderAt struct{}
 func (f *failingReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
 	return 0, io.ErrUnexpectedEOF
 }
+
+func TestReaderSkip(t *testing.T) {
+	reader := NewReader(bytes.NewReader([]byte{0x01, 0x02, 0x03, 0x04}))
+	reader.Skip(2)
+	require.Equal(t, int64(2), reader.GetOffset())
+	require.Equal(t, uint16(0x0403), reader.ReadU16(binary.LittleEndian))
+	require.NoError(t, reader.Err())
+}
+
+func TestReaderReadFull(t *testing.T) {
+	reader := NewReader(bytes.NewReader([]byte{0x01, 0x02, 0x03}))
+	buf := make([]byte, 2)
+	reader.ReadFull(buf)
+	require.NoError(t, reader.Err())
+	require.Equal(t, []byte{0x01, 0x02}, buf)
+	require.Equal(t, int64(2), reader.GetOffset())
+
+	reader.ReadFull(buf)
+	require.ErrorIs(t, reader.Err(), io.ErrUnexpectedEOF)
+}
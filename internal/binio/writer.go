@@ -6,17 +6,48 @@ import (
 	"io"
 )
 
+// ErrInvalidFourCC is returned by WriteS32 for a string that is not 4 bytes long.
+var ErrInvalidFourCC = errors.New("string must be exactly 4 characters")
+
 type Writer struct {
 	w      io.WriteSeeker
 	offset int64
 	err    error
+	buf    []byte  // pending output of a buffered Writer
+	tmp    [4]byte // scratch space for fixed-size fields
 }
 
 func NewWriter(w io.WriteSeeker) *Writer {
 	return &Writer{w: w}
 }
 
+// NewBufferedWriter returns a Writer that collects up to size bytes before
+// writing them to w. Pending bytes are written by Flush and before every
+// SetOffset, so Flush must be called after the last write.
+func NewBufferedWriter(w io.WriteSeeker, size int) *Writer {
+	return &Writer{w: w, buf: make([]byte, 0, size)}
+}
+
 func (bw *Writer) write(b []byte) {
+	if bw.err != nil {
+		return
+	}
+	if cap(bw.buf) != 0 {
+		if len(bw.buf)+len(b) > cap(bw.buf) {
+			bw.flush()
+		}
+		if len(b) < cap(bw.buf) {
+			bw.buf = append(bw.buf, b...)
+			bw.offset += int64(len(b))
+			return
+		}
+	}
+	bw.writeThrough(b)
+	bw.offset += int64(len(b))
+}
+
+// writeThrough writes b to the underlying writer.
+func (bw *Writer) writeThrough(b []byte) {
 	if bw.err != nil {
 		return
 	}
@@ -27,23 +58,42 @@ func (bw *Writer) write(b []byte) {
 	}
 	if n != len(b) {
 		bw.err = io.ErrShortWrite
+	}
+}
+
+func (bw *Writer) flush() {
+	if len(bw.buf) == 0 {
 		return
 	}
-	bw.offset += int64(n)
+	bw.writeThrough(bw.buf)
+	bw.buf = bw.buf[:0]
+}
+
+// Flush writes any buffered bytes to the underlying writer.
+func (bw *Writer) Flush() error {
+	if bw.err == nil {
+		bw.flush()
+	}
+	return bw.err
+}
+
+func (bw *Writer) WriteRaw(b []byte) {
+	bw.write(b)
 }
 
 func (bw *Writer) WriteU8(v uint8) {
-	bw.write([]byte{v})
+	bw.tmp[0] = v
+	bw.write(bw.tmp[:1])
 }
 
 func (bw *Writer) WriteU16(v uint16, order binary.ByteOrder) {
-	buf := make([]byte, 2)
+	buf := bw.tmp[:2]
 	order.PutUint16(buf, v)
 	bw.write(buf)
 }
 
 func (bw *Writer) WriteU24(v uint32, order binary.ByteOrder) {
-	buf := make([]byte, 3)
+	buf := bw.tmp[:3]
 	if order == binary.LittleEndian {
 		buf[0] = byte(v)
 		buf[1] = byte(v >> 8)
@@ -57,7 +107,7 @@ func (bw *Writer) WriteU24(v uint32, order binary.ByteOrder) {
 }
 
 func (bw *Writer) WriteU32(v uint32, order binary.ByteOrder) {
-	buf := make([]byte, 4)
+	buf := bw.tmp[:4]
 	order.PutUint32(buf, v)
 	bw.write(buf)
 }
@@ -65,13 +115,17 @@ func (bw *Writer) WriteU32(v uint32, order binary.ByteOrder) {
 func (bw *Writer) WriteS32(s string, order binary.ByteOrder) {
 	_ = order // order is ignored for strings
 	if len(s) != 4 {
-		bw.err = errors.New("string must be exactly 4 characters")
+		bw.err = ErrInvalidFourCC
 		return
 	}
-	bw.write([]byte(s))
+	bw.write(bw.tmp[:copy(bw.tmp[:], s)])
 }
 
 func (bw *Writer) SetOffset(off int64) {
+	if bw.err != nil {
+		return
+	}
+	bw.flush()
 	if bw.err != nil {
 		return
 	}
@@ -210,6 +210,44 @@ func TestWriterSeekError(t *testing.T) {
 	require.Equal(t, originalOffset, writer.GetOffset())
 }
 
+func TestBufferedWriter(t *testing.T) {
+	buf := &seekableBuffer{}
+	writer := NewBufferedWriter(buf, 8)
+
+	writer.WriteU32(0x04030201, binary.LittleEndian)
+	writer.WriteU16(0x0605, binary.LittleEndian)
+	require.NoError(t, writer.Err())
+	require.Empty(t, buf.Bytes())
+	require.Equal(t, int64(6), writer.GetOffset())
+
+	// Overflowing the buffer writes the pending bytes first.
+	writer.WriteU32(0x0A090807, binary.LittleEndian)
+	require.Equal(t, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, buf.Bytes())
+
+	// Writes as large as the buffer bypass it.
+	writer.WriteRaw([]byte{0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10, 0x11, 0x12})
+	require.Len(t, buf.Bytes(), 18)
+
+	// Seeking writes the pending bytes before moving.
+	writer.SetOffset(0)
+	writer.WriteU8(0xFF)
+	require.NoError(t, writer.Flush())
+	require.Equal(t, []byte{0xFF}, buf.Bytes())
+	require.Equal(t, []byte{
+		0xFF, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09,
+		0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10, 0x11, 0x12,
+	}, buf.data)
+	require.Equal(t, int64(1), writer.GetOffset())
+}
+
+func TestBufferedWriterError(t *testing.T) {
+	writer := NewBufferedWriter(&failingWriteSeeker{}, 8)
+	writer.WriteU8(0x01)
+	require.NoError(t, writer.Err())
+	require.ErrorIs(t, writer.Flush(), io.ErrClosedPipe)
+	require.Error(t, writer.Err())
+}
+
 func TestWriterU24EdgeCases(t *testing.T) {
 	t.Run("MaxValue_LittleEndian", func(t *testing.T) {
 		buf := &seekableBuffer{}
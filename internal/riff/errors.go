@@ -0,0 +1,70 @@
+package riff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Sentinel errors classifying why a file could not be parsed. They are
+// wrapped by *ParseError, so test for them with errors.Is.
+var (
+	// ErrNotRIFF is returned when a file does not start with a RIFF, RF64 or BW64 header.
+	ErrNotRIFF = errors.New("not a RIFF file")
+
+	// ErrNotWAVE is returned when a RIFF-style file carries a form type other than WAVE, such as AVI or WebP.
+	ErrNotWAVE = errors.New("RIFF form type is not WAVE")
+
+	// ErrChunkNotFound is returned when a required chunk is missing.
+	ErrChunkNotFound = errors.New("chunk not found")
+
+	// ErrInvalidChunkSize is returned when a chunk size exceeds the bytes left in its parent.
+	ErrInvalidChunkSize = errors.New("invalid chunk size")
+
+	// ErrInvalidChunk is returned when the data of a chunk is malformed.
+	ErrInvalidChunk = errors.New("invalid chunk")
+
+	// ErrTruncated is returned when a file ends in the middle of a chunk.
+	ErrTruncated = errors.New("unexpected end of file")
+)
+
+// ParseError describes why a chunk of a file could not be parsed.
+type ParseError struct {
+	// ChunkID is the ID of the chunk, or empty for the RIFF header.
+	ChunkID string
+
+	// Offset is the position of the chunk header in the file, or -1 if it
+	// is unknown, e.g. for a missing chunk.
+	Offset int64
+
+	// Reason describes the problem.
+	Reason string
+
+	// Err is one of the sentinel errors of this package.
+	Err error
+}
+
+// NewParseError returns a *ParseError wrapping err.
+func NewParseError(err error, chunkID string, offset int64, reason string) *ParseError {
+	return &ParseError{ChunkID: chunkID, Offset: offset, Reason: reason, Err: err}
+}
+
+func (e *ParseError) Error() string {
+	if e.Offset < 0 {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s at offset %d", e.Reason, e.Offset)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// readError converts an error of a binio.Reader into a *ParseError if the
+// chunk at offset was cut short, and returns other I/O errors unchanged.
+func readError(err error, chunkID string, offset int64) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return NewParseError(ErrTruncated, chunkID, offset, "unexpected end of file")
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package riff
+
+import "encoding/binary"
+
+const (
+	LISTChunkID  string = "LIST"
+	INFOListType string = "INFO"
+)
+
+// ParseList splits the payload of a LIST chunk into its list type and
+// sub-chunks. Odd-sized sub-chunks are followed by a pad byte.
+func ParseList(data []byte) (string, []*Chunk, error) {
+	return ParseListAt(data, -1)
+}
+
+// ParseListAt is like ParseList for the payload of a LIST chunk located at
+// offset in the file, which also sets the Offset and Length of the
+// sub-chunks and the Offset of a *ParseError.
+func ParseListAt(data []byte, offset int64) (string, []*Chunk, error) {
+	// subOffset returns the file offset of the sub-chunk at off in data.
+	subOffset := func(off int) int64 {
+		if offset < 0 {
+			return -1
+		}
+		return offset + 8 + int64(off)
+	}
+	if len(data) < 4 {
+		return "", nil, NewParseError(ErrInvalidChunk, LISTChunkID, offset, "invalid LIST chunk: too short")
+	}
+	listType := string(data[0:4])
+	chunks := make([]*Chunk, 0)
+	for off := 4; off < len(data); {
+		if len(data)-off < 8 {
+			return "", nil, NewParseError(ErrInvalidChunk, LISTChunkID, offset, "invalid LIST chunk: truncated sub-chunk header")
+		}
+		id := string(data[off : off+4])
+		size := binary.LittleEndian.Uint32(data[off+4 : off+8])
+		if uint64(size) > uint64(len(data)-off-8) {
+			return "", nil, NewParseError(ErrInvalidChunkSize, id, subOffset(off), "invalid chunk size: exceeds remaining bytes")
+		}
+		c := &Chunk{ID: id, Size: size, Data: data[off+8 : off+8+int(size)]}
+		if offset >= 0 {
+			c.Offset = subOffset(off)
+			c.Length = int64(size)
+		}
+		chunks = append(chunks, c)
+		off += 8 + int(size) + int(size&1)
+	}
+	return listType, chunks, nil
+}
+
+// EncodeChunk serialises a chunk header and data, appending the pad byte
+// required after odd-sized data.
+func EncodeChunk(id string, data []byte) []byte {
+	buf := make([]byte, 8, 8+len(data)+1)
+	copy(buf[0:4], id)
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(len(data)))
+	buf = append(buf, data...)
+	if len(data)%2 == 1 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// EncodeList returns the payload of a LIST chunk of the given type
+// containing the given sub-chunks.
+func EncodeList(listType string, chunks []*Chunk) []byte {
+	buf := []byte(listType)
+	for _, c := range chunks {
+		buf = append(buf, EncodeChunk(c.ID, c.Data)...)
+	}
+	return buf
+}
@@ -0,0 +1,55 @@
+package riff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeChunkPadding(t *testing.T) {
+	require.Equal(t, []byte{'t', 'e', 's', 't', 0x02, 0x00, 0x00, 0x00, 0x01, 0x02}, EncodeChunk("test", []byte{0x01, 0x02}))
+	require.Equal(t, []byte{'t', 'e', 's', 't', 0x01, 0x00, 0x00, 0x00, 0x01, 0x00}, EncodeChunk("test", []byte{0x01}))
+}
+
+func TestParseListRoundTrip(t *testing.T) {
+	chunks := []*Chunk{
+		{ID: "INAM", Size: 3, Data: []byte("ab\x00")},
+		{ID: "IART", Size: 4, Data: []byte("abc\x00")},
+	}
+	data := EncodeList(INFOListType, chunks)
+	require.Len(t, data, 4+8+4+8+4)
+
+	listType, got, err := ParseList(data)
+	require.NoError(t, err)
+	require.Equal(t, INFOListType, listType)
+	require.Equal(t, chunks, got)
+}
+
+func TestParseListInvalid(t *testing.T) {
+	_, _, err := ParseList([]byte("IN"))
+	require.Error(t, err)
+
+	_, _, err = ParseList([]byte("INFOINAM\x10\x00\x00\x00ab"))
+	require.EqualError(t, err, "invalid chunk size: exceeds remaining bytes")
+
+	_, _, err = ParseList([]byte("INFOINAM"))
+	require.Error(t, err)
+}
+
+func TestParseListAt(t *testing.T) {
+	_, chunks, err := ParseListAt([]byte("INFOINAM\x01\x00\x00\x00a\x00ICMT\x02\x00\x00\x00bc"), 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(112), chunks[0].Offset)
+	require.Equal(t, int64(122), chunks[1].Offset)
+	require.Equal(t, int64(2), chunks[1].Length)
+
+	_, _, err = ParseListAt([]byte("INFOINAM\x01\x00\x00\x00a\x00ICMT\x10\x00\x00\x00bc"), 100)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, "ICMT", parseErr.ChunkID)
+	require.Equal(t, int64(122), parseErr.Offset)
+
+	_, _, err = ParseListAt([]byte("IN"), 100)
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, int64(100), parseErr.Offset)
+}
@@ -2,13 +2,55 @@ package riff
 
 import (
 	"encoding/binary"
-	"errors"
+	"fmt"
 	"io"
 
 	"github.com/takurooo/wavgo/internal/binio"
 )
 
+// ReadRIFFChunk reads the RIFF header and all sub-chunks, loading the data of
+// every sub-chunk into memory.
 func ReadRIFFChunk(r io.ReaderAt) (*riffChunk, error) {
+	return readRIFFChunk(r, readOptions{loadData: true})
+}
+
+// ScanRIFFChunk reads the RIFF header and the headers of all sub-chunks
+// without loading their data. The Data of every sub-chunk except ds64 is nil.
+func ScanRIFFChunk(r io.ReaderAt) (*riffChunk, error) {
+	return readRIFFChunk(r, readOptions{})
+}
+
+// ReadOptions configures ReadRIFFChunkWithOptions and ScanRIFFChunkWithOptions.
+type ReadOptions struct {
+	// Lenient repairs the damage typically left by an interrupted recording
+	// instead of failing: the RIFF size and chunk sizes are clamped to Size,
+	// a data chunk whose size was never written is extended to the end of
+	// the file, and garbage after the last chunk is skipped. Every repair is
+	// recorded in the Warnings of the returned chunk.
+	Lenient bool
+	// Size is the size of the source in bytes. It is required in lenient mode.
+	Size int64
+}
+
+// ReadRIFFChunkWithOptions reads a RIFF chunk like ReadRIFFChunk, configured
+// by opts.
+func ReadRIFFChunkWithOptions(r io.ReaderAt, opts ReadOptions) (*riffChunk, error) {
+	return readRIFFChunk(r, readOptions{loadData: true, lenient: opts.Lenient, size: opts.Size})
+}
+
+// ScanRIFFChunkWithOptions scans a RIFF chunk like ScanRIFFChunk, configured
+// by opts.
+func ScanRIFFChunkWithOptions(r io.ReaderAt, opts ReadOptions) (*riffChunk, error) {
+	return readRIFFChunk(r, readOptions{lenient: opts.Lenient, size: opts.Size})
+}
+
+type readOptions struct {
+	loadData bool
+	lenient  bool
+	size     int64
+}
+
+func readRIFFChunk(r io.ReaderAt, opts readOptions) (*riffChunk, error) {
 	breader := binio.NewReader(r)
 	// ----------------------------
 	// Read RIFF Chunk
@@ -19,33 +61,147 @@ func ReadRIFFChunk(r io.ReaderAt) (*riffChunk, error) {
 		format    = breader.ReadS32(binary.BigEndian)
 	)
 	if breader.Err() != nil {
-		return nil, breader.Err()
+		return nil, readError(breader.Err(), "", 0)
 	}
+	if chunkID != RIFFChunkID && chunkID != RF64ChunkID && chunkID != BW64ChunkID {
+		return nil, NewParseError(ErrNotRIFF, "", 0, "not found riff chunk")
+	}
+	if format != WAVEFormType {
+		return nil, NewParseError(ErrNotWAVE, chunkID, 0, fmt.Sprintf("unsupported form type %q: not a WAVE file", format))
+	}
+	riffChunk := &riffChunk{ID: chunkID, Size: chunkSize, Format: format, SubChunks: make([]*Chunk, 0)}
+	// ----------------------------
+	// Read ds64 Chunk (RF64/BW64 only)
+	// ----------------------------
+	numBytesLeft := uint64(riffChunk.Size) - 4
+	var ds64DataSize uint64
 	if chunkID != RIFFChunkID {
-		return nil, errors.New("not found riff chunk")
+		var (
+			offset       = breader.GetOffset()
+			subChunkID   = breader.ReadS32(binary.BigEndian)
+			subChunkSize = breader.ReadU32(binary.LittleEndian)
+		)
+		if breader.Err() != nil {
+			return nil, readError(breader.Err(), subChunkID, offset)
+		}
+		if subChunkID != DS64ChunkID || subChunkSize < 24 {
+			return nil, NewParseError(ErrChunkNotFound, DS64ChunkID, offset, "not found ds64 chunk")
+		}
+		// Read the fixed fields first to check the size of the rest against
+		// the RIFF size before allocating it.
+		chunkData := breader.ReadRaw(24)
+		if breader.Err() != nil {
+			return nil, readError(breader.Err(), subChunkID, offset)
+		}
+		riffSize := binary.LittleEndian.Uint64(chunkData[0:8])
+		ds64DataSize = binary.LittleEndian.Uint64(chunkData[8:16])
+		if chunkSize == sizePlaceholder {
+			if riffSize < 4 {
+				return nil, NewParseError(ErrInvalidChunkSize, chunkID, 0, "invalid RIFF size in ds64 chunk")
+			}
+			numBytesLeft = riffSize - 4
+		}
+		// The sizes are compared without adding to them, as the 64-bit sizes
+		// of a corrupt ds64 chunk would overflow.
+		if numBytesLeft < 8 || uint64(subChunkSize) > numBytesLeft-8 {
+			return nil, NewParseError(ErrInvalidChunkSize, subChunkID, offset, "invalid chunk size: exceeds remaining bytes")
+		}
+		chunkData = append(chunkData, breader.ReadRaw(uint64(subChunkSize)-24)...)
+		if breader.Err() != nil {
+			return nil, readError(breader.Err(), subChunkID, offset)
+		}
+		c := riffChunk.AddSubChunk(subChunkID, subChunkSize, chunkData)
+		c.Offset = offset
+		c.Length = int64(subChunkSize)
+		numBytesLeft -= uint64(subChunkSize) + 8
+	}
+	if opts.lenient {
+		avail := uint64(max(opts.size-breader.GetOffset(), 0))
+		if chunkSize < 4 || numBytesLeft > avail {
+			riffChunk.warnf("RIFF size %d does not match file size %d: clamped to %d", chunkSize, opts.size, opts.size-8)
+			numBytesLeft = avail
+		}
 	}
-	riffChunk := &riffChunk{chunkID, chunkSize, format, make([]*Chunk, 0)}
 	// ----------------------------
 	// Read SubChunks
 	// ----------------------------
-	numBytesLeft := riffChunk.Size - 4
 	for 0 < numBytesLeft {
+		offset := breader.GetOffset()
+		if opts.lenient && numBytesLeft < 8 {
+			riffChunk.warnf("skipped %d trailing bytes at offset %d", numBytesLeft, offset)
+			break
+		}
 		var (
 			subChunkID   = breader.ReadS32(binary.BigEndian)
 			subChunkSize = breader.ReadU32(binary.LittleEndian)
-			chunkData    = breader.ReadRaw(uint64(subChunkSize))
+			dataSize     = uint64(subChunkSize)
 		)
+		if subChunkID == DATAChunkID && subChunkSize == sizePlaceholder && chunkID != RIFFChunkID {
+			dataSize = ds64DataSize
+		}
+		if opts.lenient {
+			if !isValidChunkID(subChunkID) {
+				riffChunk.warnf("skipped %d bytes of garbage at offset %d", numBytesLeft, offset)
+				break
+			}
+			avail := numBytesLeft - 8
+			if dataSize > avail {
+				riffChunk.warnf("%q chunk size %d exceeds the %d remaining bytes: clamped", subChunkID, dataSize, avail)
+				dataSize = avail
+			} else if subChunkID == DATAChunkID && dataSize == 0 && 0 < avail && !hasChunkAt(r, offset+8) {
+				riffChunk.warnf("%q chunk size is 0: extended to %d bytes", subChunkID, avail)
+				dataSize = avail
+			}
+		}
 		if breader.Err() != nil {
-			return nil, breader.Err()
+			return nil, readError(breader.Err(), subChunkID, offset)
 		}
-
-		chunkOverhead := uint32(8) // 4 bytes ID + 4 bytes size
-		if subChunkSize+chunkOverhead > numBytesLeft {
-			return nil, errors.New("invalid chunk size: exceeds remaining bytes")
+		chunkOverhead := uint64(8) // 4 bytes ID + 4 bytes size
+		if numBytesLeft < chunkOverhead || dataSize > numBytesLeft-chunkOverhead {
+			return nil, NewParseError(ErrInvalidChunkSize, subChunkID, offset, "invalid chunk size: exceeds remaining bytes")
+		}
+		var chunkData []byte
+		if opts.loadData {
+			chunkData = breader.ReadRaw(dataSize)
+		} else {
+			breader.Skip(dataSize)
 		}
+		if breader.Err() != nil {
+			return nil, readError(breader.Err(), subChunkID, offset)
+		}
+
+		c := riffChunk.AddSubChunk(subChunkID, subChunkSize, chunkData)
+		c.Offset = offset
+		c.Length = int64(dataSize)
+		numBytesLeft -= dataSize + chunkOverhead
 
-		riffChunk.AddSubChunk(subChunkID, subChunkSize, chunkData)
-		numBytesLeft -= subChunkSize + chunkOverhead
+		// Odd-sized chunks are followed by a pad byte that is not counted in
+		// the chunk size. Some writers omit the pad after the last chunk and
+		// leave it out of the RIFF size, so only skip it when it is counted.
+		if dataSize%2 == 1 && 0 < numBytesLeft {
+			breader.Skip(1)
+			numBytesLeft--
+		}
 	}
 	return riffChunk, nil
 }
+
+// isValidChunkID reports whether id consists of printable ASCII characters,
+// as every registered chunk ID does.
+func isValidChunkID(id string) bool {
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x20 || 0x7E < id[i] {
+			return false
+		}
+	}
+	return len(id) == 4
+}
+
+// hasChunkAt reports whether a plausible chunk header starts at off.
+func hasChunkAt(r io.ReaderAt, off int64) bool {
+	b := make([]byte, 4)
+	if _, err := r.ReadAt(b, off); err != nil {
+		return false
+	}
+	return isValidChunkID(string(b))
+}
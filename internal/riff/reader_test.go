@@ -70,7 +70,8 @@ func TestReadRIFFChunkInvalidRIFFHeader(t *testing.T) {
 
 	require.Error(t, err)
 	require.Nil(t, riffChunk)
-	require.EqualError(t, err, "not found riff chunk")
+	require.EqualError(t, err, "not found riff chunk at offset 0")
+	require.ErrorIs(t, err, ErrNotRIFF)
 }
 
 func TestReadRIFFChunkTruncatedHeader(t *testing.T) {
@@ -83,6 +84,7 @@ func TestReadRIFFChunkTruncatedHeader(t *testing.T) {
 
 	require.Error(t, err)
 	require.Nil(t, riffChunk)
+	require.ErrorIs(t, err, ErrTruncated)
 }
 
 func TestReadRIFFChunkInvalidSubchunkSize(t *testing.T) {
@@ -163,6 +165,11 @@ func TestReadRIFFChunkTruncatedSubchunk(t *testing.T) {
 
 	require.Error(t, err)
 	require.Nil(t, riffChunk)
+	require.ErrorIs(t, err, ErrInvalidChunkSize)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, "test", parseErr.ChunkID)
+	require.Equal(t, int64(12), parseErr.Offset)
 }
 
 func TestReadRIFFChunkReadError(t *testing.T) {
@@ -180,3 +187,213 @@ type failingReaderAt struct{}
 func (f *failingReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
 	return 0, io.ErrUnexpectedEOF
 }
+
+func TestReadRIFFChunkNotWAVE(t *testing.T) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(4))
+	buf.WriteString("AVI ")
+
+	riffChunk, err := ReadRIFFChunk(bytes.NewReader(buf.Bytes()))
+	require.Error(t, err)
+	require.Nil(t, riffChunk)
+	require.Contains(t, err.Error(), "not a WAVE file")
+	require.ErrorIs(t, err, ErrNotWAVE)
+}
+
+func TestReadRIFFChunkRF64MissingDS64(t *testing.T) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("RF64")
+	binary.Write(buf, binary.LittleEndian, uint32(0xFFFFFFFF))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(0))
+
+	riffChunk, err := ReadRIFFChunk(bytes.NewReader(buf.Bytes()))
+	require.Error(t, err)
+	require.Nil(t, riffChunk)
+	require.EqualError(t, err, "not found ds64 chunk at offset 12")
+	require.ErrorIs(t, err, ErrChunkNotFound)
+}
+
+func TestReadRIFFChunkRF64InvalidDS64(t *testing.T) {
+	build := func(ds64Size uint32, riffSize, dataSize uint64) []byte {
+		buf := &bytes.Buffer{}
+		buf.WriteString("RF64")
+		binary.Write(buf, binary.LittleEndian, uint32(0xFFFFFFFF))
+		buf.WriteString("WAVE")
+		buf.WriteString("ds64")
+		binary.Write(buf, binary.LittleEndian, ds64Size)
+		binary.Write(buf, binary.LittleEndian, riffSize)
+		binary.Write(buf, binary.LittleEndian, dataSize)
+		binary.Write(buf, binary.LittleEndian, uint64(0)) // sample count
+		buf.WriteString("data")
+		binary.Write(buf, binary.LittleEndian, uint32(0xFFFFFFFF))
+		buf.Write([]byte{0x01, 0x02, 0x03, 0x04})
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+		err   error
+	}{
+		{"HugeDataSize", build(24, 48, 0xFFFFFFFFFFFFFFFF), ErrInvalidChunkSize},
+		{"HugeDS64Size", build(0xFFFFFFF0, 48, 4), ErrInvalidChunkSize},
+		{"RIFFSizeUnderflow", build(24, 2, 4), ErrInvalidChunkSize},
+		{"HugeRIFFSize", build(24, 0xFFFFFFFFFFFFFFFF, 0x7FFFFFFFFFFFFFFF), ErrTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			riffChunk, err := ReadRIFFChunk(bytes.NewReader(tt.input))
+			require.ErrorIs(t, err, tt.err)
+			require.Nil(t, riffChunk)
+		})
+	}
+}
+
+func TestReadRIFFChunkOffsets(t *testing.T) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(28))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(4))
+	buf.Write([]byte{0x01, 0x02, 0x03, 0x04})
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(4))
+	buf.Write([]byte{0x05, 0x06, 0x07, 0x08})
+
+	riffChunk, err := ReadRIFFChunk(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(12), riffChunk.SubChunks[0].Offset)
+	require.Equal(t, int64(24), riffChunk.SubChunks[1].Offset)
+}
+
+func TestScanRIFFChunk(t *testing.T) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(28))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(4))
+	buf.Write([]byte{0x01, 0x02, 0x03, 0x04})
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(4))
+	buf.Write([]byte{0x05, 0x06, 0x07, 0x08})
+
+	riffChunk, err := ScanRIFFChunk(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, riffChunk.SubChunks, 2)
+	require.Equal(t, "data", riffChunk.SubChunks[1].ID)
+	require.Equal(t, uint32(4), riffChunk.SubChunks[1].Size)
+	require.Equal(t, int64(24), riffChunk.SubChunks[1].Offset)
+	require.Equal(t, int64(4), riffChunk.SubChunks[1].Length)
+	require.Nil(t, riffChunk.SubChunks[1].Data)
+}
+
+func TestReadRIFFChunkPadByte(t *testing.T) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(40))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(4))
+	buf.Write([]byte{0x01, 0x02, 0x03, 0x04})
+	buf.WriteString("odd ")
+	binary.Write(buf, binary.LittleEndian, uint32(3))
+	buf.Write([]byte{0x0A, 0x0B, 0x0C, 0x00}) // 3 bytes + pad byte
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(4))
+	buf.Write([]byte{0x05, 0x06, 0x07, 0x08})
+
+	riffChunk, err := ReadRIFFChunk(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, riffChunk.SubChunks, 3)
+	require.Equal(t, []byte{0x0A, 0x0B, 0x0C}, riffChunk.SubChunks[1].Data)
+	require.Equal(t, int64(36), riffChunk.SubChunks[2].Offset)
+	require.Equal(t, []byte{0x05, 0x06, 0x07, 0x08}, riffChunk.SubChunks[2].Data)
+}
+
+func TestReadRIFFChunkFinalPadByte(t *testing.T) {
+	build := func(riffSize uint32, pad bool) []byte {
+		buf := &bytes.Buffer{}
+		buf.WriteString("RIFF")
+		binary.Write(buf, binary.LittleEndian, riffSize)
+		buf.WriteString("WAVE")
+		buf.WriteString("fmt ")
+		binary.Write(buf, binary.LittleEndian, uint32(4))
+		buf.Write([]byte{0x01, 0x02, 0x03, 0x04})
+		buf.WriteString("data")
+		binary.Write(buf, binary.LittleEndian, uint32(3))
+		buf.Write([]byte{0x05, 0x06, 0x07})
+		if pad {
+			buf.WriteByte(0)
+		}
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		riffSize uint32
+		pad      bool
+	}{
+		{"counted pad", 28, true},
+		{"uncounted pad", 27, true},
+		{"missing pad", 27, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			riffChunk, err := ReadRIFFChunk(bytes.NewReader(build(tt.riffSize, tt.pad)))
+			require.NoError(t, err)
+			dataChunk, err := riffChunk.GetDataChunk()
+			require.NoError(t, err)
+			require.Equal(t, []byte{0x05, 0x06, 0x07}, dataChunk.Data)
+		})
+	}
+}
+
+func TestReadRIFFChunkLenient(t *testing.T) {
+	build := func(riffSize, dataSize uint32, trailer []byte) []byte {
+		buf := &bytes.Buffer{}
+		buf.WriteString("RIFF")
+		binary.Write(buf, binary.LittleEndian, riffSize)
+		buf.WriteString("WAVE")
+		buf.WriteString("fmt ")
+		binary.Write(buf, binary.LittleEndian, uint32(4))
+		buf.Write([]byte{0x01, 0x02, 0x03, 0x04})
+		buf.WriteString("data")
+		binary.Write(buf, binary.LittleEndian, dataSize)
+		buf.Write([]byte{0x05, 0x06, 0x07, 0x08, 0x09, 0x0A})
+		buf.Write(trailer)
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		input    []byte
+		data     []byte
+		warnings int
+	}{
+		{"well-formed", build(30, 6, nil), []byte{0x05, 0x06, 0x07, 0x08, 0x09, 0x0A}, 0},
+		{"unwritten sizes", build(0, 0, nil), []byte{0x05, 0x06, 0x07, 0x08, 0x09, 0x0A}, 2},
+		{"truncated data", build(1000, 980, nil), []byte{0x05, 0x06, 0x07, 0x08, 0x09, 0x0A}, 2},
+		{"trailing garbage", build(40, 6, []byte{0xFF, 0x00, 0xFE, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}), []byte{0x05, 0x06, 0x07, 0x08, 0x09, 0x0A}, 1},
+		{"trailing bytes", build(34, 6, []byte{0x00, 0x00, 0x00, 0x00}), []byte{0x05, 0x06, 0x07, 0x08, 0x09, 0x0A}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(tt.input)
+			riffChunk, err := ReadRIFFChunkWithOptions(r, ReadOptions{Lenient: true, Size: r.Size()})
+			require.NoError(t, err)
+			dataChunk, err := riffChunk.GetDataChunk()
+			require.NoError(t, err)
+			require.Equal(t, tt.data, dataChunk.Data)
+			require.Len(t, riffChunk.Warnings, tt.warnings, riffChunk.Warnings)
+
+			scanned, err := ScanRIFFChunkWithOptions(r, ReadOptions{Lenient: true, Size: r.Size()})
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tt.data)), scanned.SubChunks[1].Length)
+		})
+	}
+}
@@ -1,18 +1,33 @@
 package riff
 
-import "errors"
+import "fmt"
 
 const (
 	RIFFChunkID string = "RIFF"
+	RF64ChunkID string = "RF64"
+	BW64ChunkID string = "BW64"
+	DS64ChunkID string = "ds64"
 	FMTChunkID  string = "fmt "
 	DATAChunkID string = "data"
+
+	WAVEFormType string = "WAVE"
 )
 
+// sizePlaceholder marks a 32-bit size field whose real value is stored
+// in the ds64 chunk of an RF64/BW64 file.
+const sizePlaceholder uint32 = 0xFFFFFFFF
+
 // Chunk ...
 type Chunk struct {
 	ID   string
 	Size uint32
 	Data []byte
+	// Offset is the position of the chunk header in the file.
+	Offset int64
+	// Length is the length of the chunk data as read. It differs from Size
+	// for the data chunk of an RF64/BW64 file and for chunks repaired in
+	// lenient mode.
+	Length int64
 }
 
 // RIFFChunk ...
@@ -21,10 +36,18 @@ type riffChunk struct {
 	Size      uint32
 	Format    string
 	SubChunks []*Chunk
+	// Warnings lists the repairs applied in lenient mode.
+	Warnings []string
+}
+
+func (r *riffChunk) warnf(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
 }
 
-func (r *riffChunk) AddSubChunk(id string, size uint32, data []byte) {
-	r.SubChunks = append(r.SubChunks, &Chunk{id, size, data})
+func (r *riffChunk) AddSubChunk(id string, size uint32, data []byte) *Chunk {
+	c := &Chunk{ID: id, Size: size, Data: data}
+	r.SubChunks = append(r.SubChunks, c)
+	return c
 }
 
 func (r *riffChunk) GetFMTChunk() (*Chunk, error) {
@@ -33,7 +56,7 @@ func (r *riffChunk) GetFMTChunk() (*Chunk, error) {
 			return c, nil
 		}
 	}
-	return nil, errors.New("not found FMTChunk")
+	return nil, NewParseError(ErrChunkNotFound, FMTChunkID, -1, "not found FMTChunk")
 }
 
 func (r *riffChunk) GetDataChunk() (*Chunk, error) {
@@ -42,5 +65,5 @@ func (r *riffChunk) GetDataChunk() (*Chunk, error) {
 			return c, nil
 		}
 	}
-	return nil, errors.New("not found DataChunk")
+	return nil, NewParseError(ErrChunkNotFound, DATAChunkID, -1, "not found DataChunk")
 }
@@ -0,0 +1,106 @@
+package wavgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+)
+
+// IXMLChunkID is the ID of the iXML production metadata chunk.
+const IXMLChunkID = "iXML"
+
+// IXML holds the common production fields of an iXML document as written by
+// location sound recorders. Elements without a dedicated field are kept in
+// Extra so that a document can be parsed, modified and written back.
+type IXML struct {
+	XMLName   xml.Name       `xml:"BWFXML"`
+	Version   string         `xml:"IXML_VERSION,omitempty"`
+	Project   string         `xml:"PROJECT,omitempty"`
+	Scene     string         `xml:"SCENE,omitempty"`
+	Take      string         `xml:"TAKE,omitempty"`
+	Tape      string         `xml:"TAPE,omitempty"`
+	Circled   string         `xml:"CIRCLED,omitempty"`
+	Note      string         `xml:"NOTE,omitempty"`
+	Speed     *IXMLSpeed     `xml:"SPEED,omitempty"`
+	TrackList *IXMLTrackList `xml:"TRACK_LIST,omitempty"`
+	Extra     []IXMLElement  `xml:",any"`
+}
+
+// IXMLSpeed holds the SPEED element describing frame rates and timecode.
+type IXMLSpeed struct {
+	Note                            string `xml:"NOTE,omitempty"`
+	MasterSpeed                     string `xml:"MASTER_SPEED,omitempty"`
+	CurrentSpeed                    string `xml:"CURRENT_SPEED,omitempty"`
+	TimecodeRate                    string `xml:"TIMECODE_RATE,omitempty"`
+	TimecodeFlag                    string `xml:"TIMECODE_FLAG,omitempty"`
+	FileSampleRate                  string `xml:"FILE_SAMPLE_RATE,omitempty"`
+	AudioBitDepth                   string `xml:"AUDIO_BIT_DEPTH,omitempty"`
+	DigitizerSampleRate             string `xml:"DIGITIZER_SAMPLE_RATE,omitempty"`
+	TimestampSamplesSinceMidnightHi string `xml:"TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_HI,omitempty"`
+	TimestampSamplesSinceMidnightLo string `xml:"TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_LO,omitempty"`
+	TimestampSampleRate             string `xml:"TIMESTAMP_SAMPLE_RATE,omitempty"`
+}
+
+// SamplesSinceMidnight returns the timecode of the first sample as a sample
+// count since midnight, combining the HI and LO timestamp elements.
+func (s *IXMLSpeed) SamplesSinceMidnight() (uint64, error) {
+	hi, err := strconv.ParseUint(s.TimestampSamplesSinceMidnightHi, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	lo, err := strconv.ParseUint(s.TimestampSamplesSinceMidnightLo, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return hi<<32 | lo, nil
+}
+
+// SetSamplesSinceMidnight sets the HI and LO timestamp elements from a
+// sample count since midnight.
+func (s *IXMLSpeed) SetSamplesSinceMidnight(n uint64) {
+	s.TimestampSamplesSinceMidnightHi = strconv.FormatUint(n>>32, 10)
+	s.TimestampSamplesSinceMidnightLo = strconv.FormatUint(n&0xFFFFFFFF, 10)
+}
+
+// IXMLTrackList holds the TRACK_LIST element naming the recorded tracks.
+type IXMLTrackList struct {
+	TrackCount int         `xml:"TRACK_COUNT"`
+	Tracks     []IXMLTrack `xml:"TRACK"`
+}
+
+// IXMLTrack describes one track of a TRACK_LIST.
+type IXMLTrack struct {
+	ChannelIndex    int    `xml:"CHANNEL_INDEX"`
+	InterleaveIndex int    `xml:"INTERLEAVE_INDEX"`
+	Name            string `xml:"NAME,omitempty"`
+	Function        string `xml:"FUNCTION,omitempty"`
+}
+
+// IXMLElement is an iXML element without a dedicated field in IXML.
+type IXMLElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",innerxml"`
+}
+
+// ParseIXML decodes an iXML document. Trailing NUL padding written by some
+// recorders is ignored.
+func ParseIXML(data []byte) (*IXML, error) {
+	ixml := &IXML{}
+	if err := xml.Unmarshal(bytes.TrimRight(data, "\x00"), ixml); err != nil {
+		return nil, err
+	}
+	return ixml, nil
+}
+
+// Marshal encodes the document as an indented iXML document with an XML
+// declaration.
+func (x *IXML) Marshal() ([]byte, error) {
+	body, err := xml.MarshalIndent(x, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	doc := []byte(xml.Header)
+	doc = append(doc, body...)
+	return append(doc, '\n'), nil
+}
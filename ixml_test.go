@@ -0,0 +1,118 @@
+package wavgo
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testIXML = `<?xml version="1.0" encoding="UTF-8"?>
+<BWFXML>
+	<IXML_VERSION>2.10</IXML_VERSION>
+	<PROJECT>Feature</PROJECT>
+	<SCENE>12A</SCENE>
+	<TAKE>3</TAKE>
+	<TAPE>DAY04</TAPE>
+	<SPEED>
+		<MASTER_SPEED>25/1</MASTER_SPEED>
+		<TIMECODE_RATE>25/1</TIMECODE_RATE>
+		<TIMECODE_FLAG>NDF</TIMECODE_FLAG>
+		<TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_HI>1</TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_HI>
+		<TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_LO>2</TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_LO>
+		<TIMESTAMP_SAMPLE_RATE>48000</TIMESTAMP_SAMPLE_RATE>
+	</SPEED>
+	<TRACK_LIST>
+		<TRACK_COUNT>2</TRACK_COUNT>
+		<TRACK>
+			<CHANNEL_INDEX>1</CHANNEL_INDEX>
+			<INTERLEAVE_INDEX>1</INTERLEAVE_INDEX>
+			<NAME>Boom</NAME>
+		</TRACK>
+		<TRACK>
+			<CHANNEL_INDEX>2</CHANNEL_INDEX>
+			<INTERLEAVE_INDEX>2</INTERLEAVE_INDEX>
+			<NAME>Lav</NAME>
+		</TRACK>
+	</TRACK_LIST>
+	<LOCATION><LOCATION_NAME>Stage 5</LOCATION_NAME></LOCATION>
+</BWFXML>
+`
+
+func TestParseIXML(t *testing.T) {
+	ixml, err := ParseIXML([]byte(testIXML + "\x00\x00"))
+	require.NoError(t, err)
+	require.Equal(t, "Feature", ixml.Project)
+	require.Equal(t, "12A", ixml.Scene)
+	require.Equal(t, "3", ixml.Take)
+	require.Equal(t, "DAY04", ixml.Tape)
+	require.Equal(t, "25/1", ixml.Speed.TimecodeRate)
+	n, err := ixml.Speed.SamplesSinceMidnight()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1<<32|2), n)
+	require.Equal(t, 2, ixml.TrackList.TrackCount)
+	require.Equal(t, "Lav", ixml.TrackList.Tracks[1].Name)
+	require.Len(t, ixml.Extra, 1)
+	require.Equal(t, "LOCATION", ixml.Extra[0].XMLName.Local)
+
+	_, err = ParseIXML([]byte("<BWFXML>"))
+	require.Error(t, err)
+}
+
+func TestIXMLUpdate(t *testing.T) {
+	ixml, err := ParseIXML([]byte(testIXML))
+	require.NoError(t, err)
+	ixml.Take = "4"
+	ixml.Speed.SetSamplesSinceMidnight(48000 * 3600)
+
+	doc, err := ixml.Marshal()
+	require.NoError(t, err)
+	got, err := ParseIXML(doc)
+	require.NoError(t, err)
+	require.Equal(t, "4", got.Take)
+	n, err := got.Speed.SamplesSinceMidnight()
+	require.NoError(t, err)
+	require.Equal(t, uint64(48000*3600), n)
+	require.Len(t, got.Extra, 1)
+	require.Contains(t, got.Extra[0].Content, "Stage 5")
+}
+
+func TestIXMLRoundTrip(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   2,
+		SampleRate:    48000,
+		ByteRate:      192000,
+		BlockAlign:    4,
+		BitsPerSample: 16,
+	}
+	doc := []byte(testIXML)
+	require.Zero(t, len(doc)%2)
+
+	filename := "testdata/TestIXMLRoundTrip.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetIXMLRaw(doc)
+	require.NoError(t, w.WriteSamples(make([]Sample, 2)))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, doc, r.GetIXMLRaw())
+	ixml, err := r.GetIXML()
+	require.NoError(t, err)
+	require.Equal(t, "12A", ixml.Scene)
+}
+
+func TestReaderWithoutIXML(t *testing.T) {
+	r := NewReader()
+	require.NoError(t, r.Open("testdata/read_test.wav"))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	ixml, err := r.GetIXML()
+	require.NoError(t, err)
+	require.Nil(t, ixml)
+}
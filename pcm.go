@@ -0,0 +1,183 @@
+package wavgo
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// sampleBlockFrames is the number of sample frames the Reader and Writer
+// convert at a time, bounding the size of their reusable buffers.
+const sampleBlockFrames = 4096
+
+// decodeSamples decodes len(dst) frames of little-endian PCM data from src,
+// which must hold len(dst)*numChannels samples of containerBits bits. Values
+// are shifted right by shift bits to right-justify the valid bits.
+func decodeSamples(dst []Sample, src []byte, numChannels, containerBits, shift int) {
+	p := 0
+	switch containerBits {
+	case 8:
+		for i := range dst {
+			for ch := range numChannels {
+				dst[i][ch] = int(src[p])
+				p++
+			}
+		}
+	case 16:
+		for i := range dst {
+			for ch := range numChannels {
+				dst[i][ch] = int(int16(binary.LittleEndian.Uint16(src[p:]))) >> shift
+				p += 2
+			}
+		}
+	case 24:
+		for i := range dst {
+			for ch := range numChannels {
+				b := src[p : p+3]
+				dst[i][ch] = int(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)) >> (8 + shift)
+				p += 3
+			}
+		}
+	case 32:
+		for i := range dst {
+			for ch := range numChannels {
+				dst[i][ch] = int(int32(binary.LittleEndian.Uint32(src[p:]))) >> shift
+				p += 4
+			}
+		}
+	}
+}
+
+// encodeSamples appends samples to dst as little-endian PCM data with
+// containerBits bits per sample, shifting each value left by shift bits to
+// left-justify the valid bits in the container.
+func encodeSamples(dst []byte, samples []Sample, numChannels, containerBits, shift int) []byte {
+	switch containerBits {
+	case 8:
+		for _, s := range samples {
+			for ch := range numChannels {
+				dst = append(dst, uint8(s[ch]))
+			}
+		}
+	case 16:
+		for _, s := range samples {
+			for ch := range numChannels {
+				dst = binary.LittleEndian.AppendUint16(dst, uint16(s[ch]<<shift))
+			}
+		}
+	case 24:
+		for _, s := range samples {
+			for ch := range numChannels {
+				v := uint32(s[ch] << shift)
+				dst = append(dst, byte(v), byte(v>>8), byte(v>>16))
+			}
+		}
+	case 32:
+		for _, s := range samples {
+			for ch := range numChannels {
+				dst = binary.LittleEndian.AppendUint32(dst, uint32(s[ch]<<shift))
+			}
+		}
+	}
+	return dst
+}
+
+// decodeInterleaved decodes the samples of src into dst as interleaved
+// sample values, like decodeSamples but for any number of channels.
+func decodeInterleaved(dst []int, src []byte, containerBits, shift int) {
+	switch containerBits {
+	case 8:
+		for i := range dst {
+			dst[i] = int(src[i])
+		}
+	case 16:
+		for i := range dst {
+			dst[i] = int(int16(binary.LittleEndian.Uint16(src[2*i:]))) >> shift
+		}
+	case 24:
+		for i := range dst {
+			b := src[3*i : 3*i+3]
+			dst[i] = int(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)) >> (8 + shift)
+		}
+	case 32:
+		for i := range dst {
+			dst[i] = int(int32(binary.LittleEndian.Uint32(src[4*i:]))) >> shift
+		}
+	}
+}
+
+// encodeInterleaved appends interleaved sample values to dst, like
+// encodeSamples but for any number of channels.
+func encodeInterleaved(dst []byte, values []int, containerBits, shift int) []byte {
+	switch containerBits {
+	case 8:
+		for _, v := range values {
+			dst = append(dst, uint8(v))
+		}
+	case 16:
+		for _, v := range values {
+			dst = binary.LittleEndian.AppendUint16(dst, uint16(v<<shift))
+		}
+	case 24:
+		for _, v := range values {
+			u := uint32(v << shift)
+			dst = append(dst, byte(u), byte(u>>8), byte(u>>16))
+		}
+	case 32:
+		for _, v := range values {
+			dst = binary.LittleEndian.AppendUint32(dst, uint32(v<<shift))
+		}
+	}
+	return dst
+}
+
+// sampleScale converts between Sample values, which hold the valid bits
+// right-justified with 8-bit samples unsigned around 128, and full-scale
+// values of other sample types.
+type sampleScale struct {
+	bits int // valid bits per sample
+	bias int // 128 for unsigned 8-bit samples
+}
+
+func newSampleScale(validBits int) sampleScale {
+	s := sampleScale{bits: validBits}
+	if validBits == 8 {
+		s.bias = 128
+	}
+	return s
+}
+
+func (s sampleScale) toInt32(v int) int32 {
+	return int32(v-s.bias) << (32 - s.bits)
+}
+
+func (s sampleScale) toInt16(v int) int16 {
+	return int16(s.toInt32(v) >> 16)
+}
+
+func (s sampleScale) toFloat32(v int) float32 {
+	return float32(v-s.bias) / float32(int(1)<<(s.bits-1))
+}
+
+func (s sampleScale) fromInt32(x int32) int {
+	return int(x>>(32-s.bits)) + s.bias
+}
+
+func (s sampleScale) fromInt16(x int16) int {
+	return s.fromInt32(int32(x) << 16)
+}
+
+// fromFloat32 rounds f to the nearest sample value, clipping values outside
+// [-1, 1). NaN is converted to silence.
+func (s sampleScale) fromFloat32(f float32) int {
+	full := float64(int(1) << (s.bits - 1))
+	v := math.Round(float64(f) * full)
+	switch {
+	case v >= full:
+		v = full - 1
+	case v < -full:
+		v = -full
+	case math.IsNaN(v):
+		v = 0
+	}
+	return int(v) + s.bias
+}
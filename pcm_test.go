@@ -0,0 +1,90 @@
+package wavgo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeSamples(t *testing.T) {
+	tests := []struct {
+		name          string
+		containerBits int
+		validBits     int
+		samples       []Sample
+		encoded       []byte
+	}{
+		{"8", 8, 8, []Sample{{0, 255}, {128, 1}}, []byte{0x00, 0xFF, 0x80, 0x01}},
+		{"16", 16, 16, []Sample{{-32768, 32767}, {-1, 1}}, []byte{0x00, 0x80, 0xFF, 0x7F, 0xFF, 0xFF, 0x01, 0x00}},
+		{"12In16", 16, 12, []Sample{{-2048, 2047}}, []byte{0x00, 0x80, 0xF0, 0x7F}},
+		{"24", 24, 24, []Sample{{-8388608, 8388607}, {-1, 1}}, []byte{0x00, 0x00, 0x80, 0xFF, 0xFF, 0x7F, 0xFF, 0xFF, 0xFF, 0x01, 0x00, 0x00}},
+		{"20In24", 24, 20, []Sample{{-524288, 524287}}, []byte{0x00, 0x00, 0x80, 0xF0, 0xFF, 0x7F}},
+		{"32", 32, 32, []Sample{{-2147483648, 2147483647}}, []byte{0x00, 0x00, 0x00, 0x80, 0xFF, 0xFF, 0xFF, 0x7F}},
+		{"24In32", 32, 24, []Sample{{-8388608, 8388607}}, []byte{0x00, 0x00, 0x00, 0x80, 0x00, 0xFF, 0xFF, 0x7F}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift := tt.containerBits - tt.validBits
+			encoded := encodeSamples(nil, tt.samples, 2, tt.containerBits, shift)
+			require.Equal(t, tt.encoded, encoded)
+
+			decoded := make([]Sample, len(tt.samples))
+			decodeSamples(decoded, encoded, 2, tt.containerBits, shift)
+			require.Equal(t, tt.samples, decoded)
+		})
+	}
+}
+
+func TestSampleScale(t *testing.T) {
+	s8 := newSampleScale(8)
+	require.Equal(t, int16(-32768), s8.toInt16(0))
+	require.Equal(t, int16(0), s8.toInt16(128))
+	require.Equal(t, int32(0x7F000000), s8.toInt32(255))
+	require.Equal(t, float32(-1), s8.toFloat32(0))
+	require.Equal(t, 128, s8.fromInt16(0))
+	require.Equal(t, 255, s8.fromFloat32(1))
+
+	s16 := newSampleScale(16)
+	require.Equal(t, int16(-2), s16.toInt16(-2))
+	require.Equal(t, int32(-2<<16), s16.toInt32(-2))
+	require.Equal(t, float32(0.5), s16.toFloat32(16384))
+	require.Equal(t, -2, s16.fromInt32(-2<<16))
+	require.Equal(t, 16384, s16.fromFloat32(0.5))
+	require.Equal(t, 32767, s16.fromFloat32(2))
+	require.Equal(t, -32768, s16.fromFloat32(-2))
+	require.Equal(t, 0, s16.fromFloat32(float32(math.NaN())))
+
+	s24 := newSampleScale(24)
+	require.Equal(t, int16(0x1234), s24.toInt16(0x123456))
+	require.Equal(t, int32(0x12345600), s24.toInt32(0x123456))
+	require.Equal(t, 0x123400, s24.fromInt16(0x1234))
+	require.Equal(t, 0x123456, s24.fromInt32(0x12345600))
+}
+
+// benchmarkFormats are the stereo 48 kHz sample layouts covered by the
+// Reader and Writer benchmarks.
+var benchmarkFormats = []struct {
+	name   string
+	format *Format
+}{
+	{"8", NewPCMFormat(2, 48000, 8)},
+	{"16", NewPCMFormat(2, 48000, 16)},
+	{"20In24", NewPCMFormat(2, 48000, 20)},
+	{"24", NewPCMFormat(2, 48000, 24)},
+	{"32", NewPCMFormat(2, 48000, 32)},
+}
+
+// benchmarkSamples returns n sample frames spanning the range of format.
+func benchmarkSamples(format *Format, n int) []Sample {
+	samples := make([]Sample, n)
+	bits := int(format.BitsPerSample)
+	for i := range samples {
+		v := i * 7919 % (1 << (bits - 1))
+		if bits == 8 {
+			v = i % 256
+		}
+		samples[i] = Sample{v, -v}
+	}
+	return samples
+}
@@ -0,0 +1,293 @@
+package wavgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// PEAKChunkID is the ID of the chunk holding the peak value of each channel.
+	PEAKChunkID = "PEAK"
+	// LEVLChunkID is the ID of the EBU peak envelope chunk.
+	LEVLChunkID = "levl"
+)
+
+// Formats of a peak envelope point.
+const (
+	PeakEnvelopeFormat8Bit  = 1
+	PeakEnvelopeFormat16Bit = 2
+)
+
+// DefaultPeakEnvelopeBlockSize is the number of sample frames per peak point
+// recommended by EBU Tech 3285 Supplement 3.
+const DefaultPeakEnvelopeBlockSize = 256
+
+// levlHeaderSize is the size of the levl chunk payload preceding the peak points.
+const levlHeaderSize = 120
+
+// Peak holds the contents of a PEAK chunk.
+type Peak struct {
+	Version uint32
+
+	// TimeStamp is the time the peaks were computed, in seconds since the Unix epoch.
+	TimeStamp uint32
+
+	// Channels holds the peak of each channel.
+	Channels []ChannelPeak
+}
+
+// ChannelPeak is the peak of a single channel.
+type ChannelPeak struct {
+	// Value is the absolute peak level, where 1.0 is full scale.
+	Value float32
+
+	// Position is the sample frame at which the peak occurs.
+	Position uint32
+}
+
+// PeakEnvelope holds the contents of an EBU Tech 3285 Supplement 3 levl chunk.
+type PeakEnvelope struct {
+	Version uint32
+
+	// Format is PeakEnvelopeFormat8Bit or PeakEnvelopeFormat16Bit.
+	Format uint32
+
+	// PointsPerValue is 1 if each point is the absolute peak, or 2 if each
+	// point is a pair of positive and negative peaks.
+	PointsPerValue uint32
+
+	// BlockSize is the number of sample frames per peak point.
+	BlockSize uint32
+
+	PeakChannels  uint32
+	NumPeakFrames uint32
+
+	// PosPeakOfPeaks is the sample frame of the highest peak, or
+	// 0xFFFFFFFF if unknown.
+	PosPeakOfPeaks uint32
+
+	// TimeStamp is the creation time formatted as "yyyy:mm:dd:hh:mm:ss:uuu".
+	TimeStamp string
+
+	// Points holds the peak points interleaved by channel. Values are scaled
+	// so that the maximum of the format (0xFF or 0xFFFF) is full scale.
+	Points []uint16
+}
+
+// parsePeak decodes the payload of a PEAK chunk.
+func parsePeak(data []byte) (*Peak, error) {
+	if len(data) < 8 || (len(data)-8)%8 != 0 {
+		return nil, invalidChunk(PEAKChunkID, "bad size")
+	}
+	le := binary.LittleEndian
+	p := &Peak{
+		Version:   le.Uint32(data[0:4]),
+		TimeStamp: le.Uint32(data[4:8]),
+		Channels:  make([]ChannelPeak, (len(data)-8)/8),
+	}
+	for i := range p.Channels {
+		c := data[8+i*8:]
+		p.Channels[i] = ChannelPeak{
+			Value:    math.Float32frombits(le.Uint32(c[0:4])),
+			Position: le.Uint32(c[4:8]),
+		}
+	}
+	return p, nil
+}
+
+// encodePeak returns the payload of a PEAK chunk.
+func encodePeak(p *Peak) []byte {
+	le := binary.LittleEndian
+	data := make([]byte, 8+8*len(p.Channels))
+	le.PutUint32(data[0:4], p.Version)
+	le.PutUint32(data[4:8], p.TimeStamp)
+	for i, c := range p.Channels {
+		le.PutUint32(data[8+i*8:], math.Float32bits(c.Value))
+		le.PutUint32(data[12+i*8:], c.Position)
+	}
+	return data
+}
+
+// parsePeakEnvelope decodes the payload of a levl chunk.
+func parsePeakEnvelope(data []byte) (*PeakEnvelope, error) {
+	if len(data) < levlHeaderSize {
+		return nil, invalidChunk(LEVLChunkID, "too short")
+	}
+	le := binary.LittleEndian
+	e := &PeakEnvelope{
+		Version:        le.Uint32(data[0:4]),
+		Format:         le.Uint32(data[4:8]),
+		PointsPerValue: le.Uint32(data[8:12]),
+		BlockSize:      le.Uint32(data[12:16]),
+		PeakChannels:   le.Uint32(data[16:20]),
+		NumPeakFrames:  le.Uint32(data[20:24]),
+		PosPeakOfPeaks: le.Uint32(data[24:28]),
+		TimeStamp:      fixedString(data[32:60]),
+	}
+	// dwOffsetToPeaks is measured from the start of the chunk header.
+	offset := int64(le.Uint32(data[28:32])) - 8
+	if offset < levlHeaderSize || offset > int64(len(data)) {
+		return nil, invalidChunk(LEVLChunkID, "bad offset to peaks")
+	}
+	points := data[offset:]
+	numPoints := uint64(e.NumPeakFrames) * uint64(e.PeakChannels) * uint64(e.PointsPerValue)
+	switch e.Format {
+	case PeakEnvelopeFormat8Bit:
+		if numPoints > uint64(len(points)) {
+			return nil, invalidChunk(LEVLChunkID, "peak points exceed chunk size")
+		}
+		e.Points = make([]uint16, numPoints)
+		for i := range e.Points {
+			e.Points[i] = uint16(points[i])
+		}
+	case PeakEnvelopeFormat16Bit:
+		if numPoints*2 > uint64(len(points)) {
+			return nil, invalidChunk(LEVLChunkID, "peak points exceed chunk size")
+		}
+		e.Points = make([]uint16, numPoints)
+		for i := range e.Points {
+			e.Points[i] = le.Uint16(points[i*2:])
+		}
+	default:
+		return nil, invalidChunk(LEVLChunkID, "unknown format")
+	}
+	return e, nil
+}
+
+// encodePeakEnvelope returns the payload of a levl chunk.
+func encodePeakEnvelope(e *PeakEnvelope) []byte {
+	le := binary.LittleEndian
+	pointSize := 2
+	if e.Format == PeakEnvelopeFormat8Bit {
+		pointSize = 1
+	}
+	data := make([]byte, levlHeaderSize+pointSize*len(e.Points))
+	le.PutUint32(data[0:4], e.Version)
+	le.PutUint32(data[4:8], e.Format)
+	le.PutUint32(data[8:12], e.PointsPerValue)
+	le.PutUint32(data[12:16], e.BlockSize)
+	le.PutUint32(data[16:20], e.PeakChannels)
+	le.PutUint32(data[20:24], e.NumPeakFrames)
+	le.PutUint32(data[24:28], e.PosPeakOfPeaks)
+	le.PutUint32(data[28:32], levlHeaderSize+8)
+	putFixedString(data[32:60], e.TimeStamp)
+	// data[60:120] is reserved and must be zero.
+	for i, v := range e.Points {
+		if pointSize == 1 {
+			data[levlHeaderSize+i] = uint8(v)
+		} else {
+			le.PutUint16(data[levlHeaderSize+i*2:], v)
+		}
+	}
+	return data
+}
+
+// peakTracker computes the PEAK and levl chunks from the samples passed to
+// Writer.WriteSamples.
+type peakTracker struct {
+	numChannels int
+	unsigned    bool // 8-bit PCM is stored unsigned around 128
+	fullScale   float64
+	blockSize   uint32 // zero disables the peak envelope
+
+	frames      uint32
+	peaks       []int
+	peakPos     []uint32
+	blockPeaks  []int
+	blockFrames uint32
+	points      []uint16
+}
+
+func newPeakTracker(format *Format, blockSize uint32) (*peakTracker, error) {
+	containerBits, validBits, err := format.sampleLayout()
+	if err != nil {
+		return nil, err
+	}
+	numChannels := int(format.NumChannels)
+	return &peakTracker{
+		numChannels: numChannels,
+		unsigned:    containerBits == 8,
+		fullScale:   float64(int(1) << (validBits - 1)),
+		blockSize:   blockSize,
+		peaks:       make([]int, numChannels),
+		peakPos:     make([]uint32, numChannels),
+		blockPeaks:  make([]int, numChannels),
+	}, nil
+}
+
+// add adds one sample frame holding a value per channel.
+func (t *peakTracker) add(frame []int) {
+	for ch := 0; ch < t.numChannels; ch++ {
+		v := frame[ch]
+		if t.unsigned {
+			v -= 128
+		}
+		if v < 0 {
+			v = -v
+		}
+		if v > t.peaks[ch] {
+			t.peaks[ch] = v
+			t.peakPos[ch] = t.frames
+		}
+		if v > t.blockPeaks[ch] {
+			t.blockPeaks[ch] = v
+		}
+	}
+	t.frames++
+	if t.blockSize == 0 {
+		return
+	}
+	t.blockFrames++
+	if t.blockFrames == t.blockSize {
+		t.flushBlock()
+	}
+}
+
+func (t *peakTracker) flushBlock() {
+	for ch, v := range t.blockPeaks {
+		t.points = append(t.points, uint16(math.Min(float64(v)/t.fullScale, 1)*0xFFFF))
+		t.blockPeaks[ch] = 0
+	}
+	t.blockFrames = 0
+}
+
+// peak returns the PEAK chunk for the samples added so far.
+func (t *peakTracker) peak(now time.Time) *Peak {
+	p := &Peak{Version: 1, TimeStamp: uint32(now.Unix()), Channels: make([]ChannelPeak, t.numChannels)}
+	for ch := range p.Channels {
+		p.Channels[ch] = ChannelPeak{
+			Value:    float32(float64(t.peaks[ch]) / t.fullScale),
+			Position: t.peakPos[ch],
+		}
+	}
+	return p
+}
+
+// envelope returns the levl chunk for the samples added so far, including
+// a final partial block.
+func (t *peakTracker) envelope(now time.Time) *PeakEnvelope {
+	if t.blockFrames > 0 {
+		t.flushBlock()
+	}
+	posPeakOfPeaks := uint32(0xFFFFFFFF)
+	best := -1
+	for ch, v := range t.peaks {
+		if v > best {
+			best = v
+			posPeakOfPeaks = t.peakPos[ch]
+		}
+	}
+	return &PeakEnvelope{
+		Version:        1,
+		Format:         PeakEnvelopeFormat16Bit,
+		PointsPerValue: 1,
+		BlockSize:      t.blockSize,
+		PeakChannels:   uint32(t.numChannels),
+		NumPeakFrames:  uint32(len(t.points) / max(t.numChannels, 1)),
+		PosPeakOfPeaks: posPeakOfPeaks,
+		TimeStamp:      now.Format("2006:01:02:15:04:05:") + fmt.Sprintf("%03d", now.Nanosecond()/1e6),
+		Points:         t.points,
+	}
+}
@@ -0,0 +1,109 @@
+package wavgo
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterPeakChunks(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   2,
+		SampleRate:    48000,
+		ByteRate:      192000,
+		BlockAlign:    4,
+		BitsPerSample: 16,
+	}
+	samples := []Sample{{100, -200}, {-16384, 50}, {0, 0}, {8192, -32768}, {1, 1}}
+
+	filename := "testdata/TestWriterPeakChunks.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.EnablePeakChunk()
+	w.EnablePeakEnvelope(2)
+	require.NoError(t, w.WriteSamples(samples[:2]))
+	require.NoError(t, w.WriteSamples(samples[2:]))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+
+	got, err := r.GetSamples(len(samples))
+	require.NoError(t, err)
+	require.Equal(t, samples, got)
+
+	peak := r.GetPeak()
+	require.NotNil(t, peak)
+	require.Equal(t, uint32(1), peak.Version)
+	require.NotZero(t, peak.TimeStamp)
+	require.Equal(t, []ChannelPeak{{Value: 0.5, Position: 1}, {Value: 1, Position: 3}}, peak.Channels)
+
+	env := r.GetPeakEnvelope()
+	require.NotNil(t, env)
+	require.Equal(t, uint32(PeakEnvelopeFormat16Bit), env.Format)
+	require.Equal(t, uint32(1), env.PointsPerValue)
+	require.Equal(t, uint32(2), env.BlockSize)
+	require.Equal(t, uint32(2), env.PeakChannels)
+	require.Equal(t, uint32(3), env.NumPeakFrames)
+	require.Equal(t, uint32(3), env.PosPeakOfPeaks)
+	require.Len(t, env.TimeStamp, 23)
+	require.Equal(t, []uint16{0x7FFF, 0x018F, 0x3FFF, 0xFFFF, 0x0001, 0x0001}, env.Points)
+}
+
+func TestWriterPeakChunk8Bit(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    8000,
+		ByteRate:      8000,
+		BlockAlign:    1,
+		BitsPerSample: 8,
+	}
+
+	filename := "testdata/TestWriterPeakChunk8Bit.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.EnablePeakChunk()
+	require.NoError(t, w.WriteSamples([]Sample{{128, 0}, {64, 0}}))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, []ChannelPeak{{Value: 0.5, Position: 1}}, r.GetPeak().Channels)
+	require.Nil(t, r.GetPeakEnvelope())
+}
+
+func TestPeakEnvelopeEncodeParse(t *testing.T) {
+	env := &PeakEnvelope{
+		Version:        1,
+		Format:         PeakEnvelopeFormat8Bit,
+		PointsPerValue: 2,
+		BlockSize:      256,
+		PeakChannels:   1,
+		NumPeakFrames:  2,
+		PosPeakOfPeaks: 300,
+		TimeStamp:      "2024:01:02:03:04:05:006",
+		Points:         []uint16{0xFF, 0x10, 0x20, 0x30},
+	}
+	data := encodePeakEnvelope(env)
+	require.Len(t, data, levlHeaderSize+4)
+	got, err := parsePeakEnvelope(data)
+	require.NoError(t, err)
+	require.Equal(t, env, got)
+
+	_, err = parsePeakEnvelope(data[:levlHeaderSize-1])
+	require.EqualError(t, err, "invalid levl chunk: too short")
+	_, err = parsePeakEnvelope(data[:levlHeaderSize+3])
+	require.EqualError(t, err, "invalid levl chunk: peak points exceed chunk size")
+
+	_, err = parsePeak(make([]byte, 12))
+	require.EqualError(t, err, "invalid PEAK chunk: bad size")
+}
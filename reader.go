@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 
 	"github.com/takurooo/wavgo/internal/binio"
@@ -13,10 +15,29 @@ import (
 // Reader provides access to the samples and metadata contained in a WAV file.
 type Reader struct {
 	f              *os.File
+	src            io.ReaderAt
 	format         Format
 	numSamples     uint32
 	numSamplesLeft uint32
 	br             *binio.Reader
+	buf            []byte
+	values         []int
+	chunks         []*riff.Chunk
+	info           *Info
+	bext           *BroadcastExtension
+	cuePoints      []CuePoint
+	sampler        *Sampler
+	instrument     *Instrument
+	acid           *ACID
+	id3            *ID3Tag
+	cart           *Cart
+	peak           *Peak
+	peakEnvelope   *PeakEnvelope
+	chna           *ChannelAssignment
+	lenient        bool
+	correctFormat  bool
+	warnings       []string
+	metadataErrors []error
 }
 
 // NewReader creates a new WAV file reader instance. The returned reader
@@ -35,6 +56,7 @@ func (r *Reader) Open(filePath string) error {
 		return err
 	}
 	r.f = f
+	r.src = f
 	return nil
 }
 
@@ -47,18 +69,67 @@ func (r *Reader) Close() error {
 	return r.f.Close()
 }
 
+// SetLenient enables or disables lenient parsing. In lenient mode Load
+// recovers what it can from malformed or truncated files, such as recordings
+// interrupted by a power loss, instead of returning an error: chunk sizes are
+// clamped to the actual file length, a data chunk whose size was never
+// written extends to the end of the file, garbage after the last chunk is
+// skipped, an inconsistent BlockAlign or ByteRate is corrected as with
+// SetCorrectFormat and unparsable metadata is ignored. Each repair is
+// reported by GetWarnings. It must be called before Load.
+func (r *Reader) SetLenient(lenient bool) {
+	r.lenient = lenient
+}
+
+// SetCorrectFormat makes Load replace a BlockAlign or ByteRate that is
+// inconsistent with the other fields of the fmt chunk by the derived value
+// and report the change by GetWarnings, instead of returning a *FormatError.
+// It must be called before Load.
+func (r *Reader) SetCorrectFormat(correct bool) {
+	r.correctFormat = correct
+}
+
+// GetWarnings returns a description of every repair applied by Load in
+// lenient mode or with SetCorrectFormat, or nil if the file was well-formed.
+func (r *Reader) GetWarnings() []string {
+	return r.warnings
+}
+
+// GetMetadataErrors returns the errors of the metadata chunks that Load
+// skipped because they could not be parsed, or nil if every chunk was
+// parsed. The errors are typically *ParseError values locating the chunk.
+func (r *Reader) GetMetadataErrors() []error {
+	return r.metadataErrors
+}
+
 // Load reads and parses the WAV file structure into memory, including the
 // RIFF header, format chunk, and data chunk. This method must be called
 // after Open() and before attempting to read samples. The entire audio
-// data is loaded into memory for efficient access.
+// data is loaded into memory for efficient access, as are the metadata
+// chunks the Reader interprets. Other chunks are only read when their data
+// is requested with Chunk.Data (see GetChunks). A metadata chunk that
+// cannot be parsed does not fail Load: the chunk is skipped, the other
+// chunks are still parsed and its error is reported by GetMetadataErrors.
 func (r *Reader) Load() error {
+	if r.src == nil {
+		return ErrNotOpen
+	}
 	// ----------------------------
 	// RIFF Chunk
 	// ----------------------------
-	riffChunk, err := riff.ReadRIFFChunk(r.f)
+	opts := riff.ReadOptions{Lenient: r.lenient}
+	if r.lenient {
+		size, err := sourceSize(r.src)
+		if err != nil {
+			return err
+		}
+		opts.Size = size
+	}
+	riffChunk, err := riff.ScanRIFFChunkWithOptions(r.src, opts)
 	if err != nil {
 		return err
 	}
+	r.warnings = riffChunk.Warnings
 	// ----------------------------
 	// Format Chunk
 	// ----------------------------
@@ -66,10 +137,22 @@ func (r *Reader) Load() error {
 	if err != nil {
 		return err
 	}
+	if err := r.loadChunk(fmtChunk); err != nil {
+		return err
+	}
 
 	r.format, err = parseFormatChunkData(fmtChunk)
 	if err != nil {
-		return err
+		return chunkError(fmtChunk, err)
+	}
+	if err := r.format.validateFields(); err != nil {
+		if !r.correctFormat && !r.lenient {
+			return err
+		}
+		r.warnings = append(r.warnings, r.format.correct()...)
+		if err := r.format.validateFields(); err != nil {
+			return err
+		}
 	}
 	// ----------------------------
 	// Data Chunk
@@ -78,18 +161,337 @@ func (r *Reader) Load() error {
 	if err != nil {
 		return err
 	}
-	r.numSamples = dataChunk.Size / uint32(r.format.BlockAlign)
+	if err := r.loadChunk(dataChunk); err != nil {
+		return err
+	}
+	r.numSamples = uint32(len(dataChunk.Data) / int(r.format.BlockAlign))
 	r.numSamplesLeft = r.numSamples
 	r.br = binio.NewReader(bytes.NewReader(dataChunk.Data))
+	if n := len(dataChunk.Data) % int(r.format.BlockAlign); r.lenient && n != 0 {
+		r.warnings = append(r.warnings, fmt.Sprintf("ignored a partial sample frame of %d bytes at the end of the data chunk", n))
+	}
+	// ----------------------------
+	// Metadata Chunks
+	// ----------------------------
+	r.chunks = riffChunk.SubChunks
+	r.metadataErrors = r.loadMetadata()
+	if r.lenient {
+		for _, err := range r.metadataErrors {
+			r.warnings = append(r.warnings, fmt.Sprintf("ignored metadata: %v", err))
+		}
+	}
 	return nil
 }
 
+// sourceSize returns the size of src in bytes.
+func sourceSize(src io.ReaderAt) (int64, error) {
+	switch s := src.(type) {
+	case interface{ Size() int64 }:
+		return s.Size(), nil
+	case interface{ Stat() (os.FileInfo, error) }:
+		fi, err := s.Stat()
+		if err != nil {
+			return 0, err
+		}
+		return fi.Size(), nil
+	}
+	return 0, fmt.Errorf("%w: lenient parsing requires a source with a known size", errors.ErrUnsupported)
+}
+
+// loadMetadata parses the optional metadata chunks of the file. A chunk that
+// cannot be parsed is skipped and its error returned with the others, so
+// that one malformed chunk neither fails Load nor hides the other chunks.
+func (r *Reader) loadMetadata() []error {
+	var errs []error
+	fail := func(c *riff.Chunk, err error) {
+		errs = append(errs, chunkError(c, err))
+	}
+	// read returns c with its data, or nil if c is nil or cannot be read.
+	read := func(c *riff.Chunk) *riff.Chunk {
+		if c == nil {
+			return nil
+		}
+		if err := r.loadChunk(c); err != nil {
+			fail(c, err)
+			return nil
+		}
+		return c
+	}
+	infoChunks, err := r.findList(riff.INFOListType)
+	if err != nil {
+		errs = append(errs, err)
+	} else if infoChunks != nil {
+		r.info = parseInfo(infoChunks)
+	}
+	if c := read(r.findChunk(BEXTChunkID)); c != nil {
+		if r.bext, err = parseBroadcastExtension(c.Data); err != nil {
+			fail(c, err)
+		}
+	}
+	if c := read(r.findChunk(CUEChunkID)); c != nil {
+		adtl, err := r.findList(ADTLListType)
+		if err != nil {
+			// The cue points are still usable without their labels.
+			errs = append(errs, err)
+		}
+		if r.cuePoints, err = parseCuePoints(c.Data, adtl); err != nil {
+			fail(c, err)
+		}
+	}
+	if c := read(r.findChunk(SMPLChunkID)); c != nil {
+		if r.sampler, err = parseSampler(c.Data); err != nil {
+			fail(c, err)
+		}
+	}
+	if c := read(r.findChunk(INSTChunkID)); c != nil {
+		if r.instrument, err = parseInstrument(c.Data); err != nil {
+			fail(c, err)
+		}
+	}
+	if c := read(r.findChunk(ACIDChunkID)); c != nil {
+		if r.acid, err = parseACID(c.Data); err != nil {
+			fail(c, err)
+		}
+	}
+	if c := read(r.findChunk(CARTChunkID)); c != nil {
+		if r.cart, err = parseCart(c.Data); err != nil {
+			fail(c, err)
+		}
+	}
+	if c := read(r.findChunk(PEAKChunkID)); c != nil {
+		if r.peak, err = parsePeak(c.Data); err != nil {
+			fail(c, err)
+		}
+	}
+	if c := read(r.findChunk(LEVLChunkID)); c != nil {
+		if r.peakEnvelope, err = parsePeakEnvelope(c.Data); err != nil {
+			fail(c, err)
+		}
+	}
+	if c := read(r.findChunk(CHNAChunkID)); c != nil {
+		if r.chna, err = parseChannelAssignment(c.Data); err != nil {
+			fail(c, err)
+		}
+	}
+	id3Chunk := r.findChunk(ID3ChunkID)
+	if id3Chunk == nil {
+		id3Chunk = r.findChunk(ID3ChunkIDUpper)
+	}
+	if id3Chunk = read(id3Chunk); id3Chunk != nil {
+		if r.id3, err = parseID3(id3Chunk.Data); err != nil {
+			fail(id3Chunk, err)
+		} else {
+			if r.info == nil {
+				r.info = &Info{}
+			}
+			r.id3.mergeInto(r.info)
+		}
+	}
+	// GetIXMLRaw and GetADMRaw return these documents as read here.
+	read(r.findChunk(IXMLChunkID))
+	read(r.findChunk(AXMLChunkID))
+	return errs
+}
+
+// loadChunk reads the data of c, which Load leaves unread until the chunk is
+// needed.
+func (r *Reader) loadChunk(c *riff.Chunk) error {
+	if c.Data != nil {
+		return nil
+	}
+	br := binio.NewReader(io.NewSectionReader(r.src, c.Offset+8, c.Length))
+	data := br.ReadRaw(uint64(c.Length))
+	if err := br.Err(); err != nil {
+		return readError(c, err)
+	}
+	c.Data = data
+	return nil
+}
+
+// readError converts an io.ErrUnexpectedEOF returned while reading the data
+// of c into a *ParseError wrapping ErrTruncated.
+func readError(c *riff.Chunk, err error) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return riff.NewParseError(ErrTruncated, c.ID, c.Offset, "unexpected end of file")
+	}
+	return err
+}
+
+// chunkError sets the offset of a *ParseError returned for the data of c,
+// and its chunk ID if the parser left it empty.
+func chunkError(c *riff.Chunk, err error) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) && parseErr.Offset < 0 && (parseErr.ChunkID == c.ID || parseErr.ChunkID == "") {
+		parseErr.ChunkID = c.ID
+		parseErr.Offset = c.Offset
+	}
+	return err
+}
+
+// findChunk returns the first sub-chunk with the given ID, or nil.
+func (r *Reader) findChunk(id string) *riff.Chunk {
+	for _, c := range r.chunks {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// findList returns the sub-chunks of the first LIST chunk of the given
+// type, or nil if there is none.
+func (r *Reader) findList(listType string) ([]*riff.Chunk, error) {
+	for _, c := range r.chunks {
+		if c.ID != riff.LISTChunkID {
+			continue
+		}
+		if err := r.loadChunk(c); err != nil {
+			return nil, err
+		}
+		if len(c.Data) < 4 || string(c.Data[0:4]) != listType {
+			continue
+		}
+		_, chunks, err := riff.ParseListAt(c.Data, c.Offset)
+		if err != nil {
+			return nil, err
+		}
+		return chunks, nil
+	}
+	return nil, nil
+}
+
 // GetFormat returns the audio format information extracted from the WAV file's
 // fmt chunk, including sample rate, bit depth, and channel configuration.
 func (r *Reader) GetFormat() Format {
 	return r.format
 }
 
+// GetInfo returns the metadata of the file's LIST/INFO chunk and ID3 tag,
+// or nil if the file has neither. When both exist, values from LIST/INFO
+// take precedence and the ID3 tag only fills fields that LIST/INFO leaves
+// empty. Pictures are always taken from the ID3 tag.
+func (r *Reader) GetInfo() *Info {
+	return r.info
+}
+
+// GetBroadcastExtension returns the contents of the file's bext chunk, or nil
+// if the file is not a Broadcast Wave file.
+func (r *Reader) GetBroadcastExtension() *BroadcastExtension {
+	return r.bext
+}
+
+// GetCuePoints returns the markers and regions of the file together with
+// their labels, notes and region texts. It returns nil if the file has no
+// cue chunk.
+func (r *Reader) GetCuePoints() []CuePoint {
+	return r.cuePoints
+}
+
+// GetSampler returns the loop points and MIDI tuning of the file's smpl
+// chunk, or nil if the file has none.
+func (r *Reader) GetSampler() *Sampler {
+	return r.sampler
+}
+
+// GetInstrument returns the contents of the file's inst chunk, or nil if
+// the file has none.
+func (r *Reader) GetInstrument() *Instrument {
+	return r.instrument
+}
+
+// GetACID returns the tempo and loop information of the file's acid chunk,
+// or nil if the file has none.
+func (r *Reader) GetACID() *ACID {
+	return r.acid
+}
+
+// GetIXMLRaw returns the raw iXML document of the file, or nil if the file
+// has no iXML chunk.
+func (r *Reader) GetIXMLRaw() []byte {
+	if c := r.findChunk(IXMLChunkID); c != nil {
+		return c.Data
+	}
+	return nil
+}
+
+// GetIXML parses the file's iXML chunk. It returns nil and no error if the
+// file has no iXML chunk. Unlike the other metadata chunks, the document is
+// parsed on demand so that a malformed document does not prevent Load from
+// succeeding.
+func (r *Reader) GetIXML() (*IXML, error) {
+	raw := r.GetIXMLRaw()
+	if raw == nil {
+		return nil, nil
+	}
+	return ParseIXML(raw)
+}
+
+// GetID3 returns the file's ID3 tag, or nil if the file has none.
+func (r *Reader) GetID3() *ID3Tag {
+	return r.id3
+}
+
+// GetCart returns the contents of the file's AES46 cart chunk, or nil if
+// the file has none.
+func (r *Reader) GetCart() *Cart {
+	return r.cart
+}
+
+// GetPeak returns the per-channel peaks of the file's PEAK chunk, or nil if
+// the file has none.
+func (r *Reader) GetPeak() *Peak {
+	return r.peak
+}
+
+// GetPeakEnvelope returns the contents of the file's levl chunk, or nil if
+// the file has none.
+func (r *Reader) GetPeakEnvelope() *PeakEnvelope {
+	return r.peakEnvelope
+}
+
+// GetChannelAssignment returns the track to ADM UID mapping of the file's
+// chna chunk, or nil if the file has none.
+func (r *Reader) GetChannelAssignment() *ChannelAssignment {
+	return r.chna
+}
+
+// GetADMRaw returns the raw ADM XML document of the file's axml chunk, or
+// nil if the file has none.
+func (r *Reader) GetADMRaw() []byte {
+	if c := r.findChunk(AXMLChunkID); c != nil {
+		return c.Data
+	}
+	return nil
+}
+
+// GetADM parses the main elements of the file's ADM XML document. It returns
+// nil and no error if the file has no axml chunk. Like GetIXML, the document
+// is parsed on demand.
+func (r *Reader) GetADM() (*ADM, error) {
+	raw := r.GetADMRaw()
+	if raw == nil {
+		return nil, nil
+	}
+	return ParseADM(raw)
+}
+
+// GetChunks returns every chunk of the file in file order, including fmt,
+// data and chunks that the Reader does not interpret. The chunk data is read
+// on demand with Chunk.Data.
+func (r *Reader) GetChunks() []*Chunk {
+	chunks := make([]*Chunk, len(r.chunks))
+	for i, c := range r.chunks {
+		chunks[i] = &Chunk{
+			ID:     c.ID,
+			Size:   c.Size,
+			Offset: c.Offset,
+			length: c.Length,
+			src:    r.src,
+		}
+	}
+	return chunks
+}
+
 // GetNumSamples returns the total number of audio sample frames in the file.
 // Each sample frame contains data for all channels.
 func (r *Reader) GetNumSamples() uint32 {
@@ -106,45 +508,147 @@ func (r *Reader) GetNumSamplesLeft() uint32 {
 // Each returned Sample contains data for all channels in the audio file.
 // The method returns an error if the requested number of samples exceeds
 // the remaining samples or if numSamples is negative.
+//
+// Samples with fewer valid bits than their container (for example 20-bit
+// audio in a 24-bit container) are returned right-justified, so a 20-bit
+// sample ranges from -2^19 to 2^19-1. Files with more than two channels
+// return ErrTooManyChannels; read them with ReadInt32 or the other
+// interleaved methods.
 func (r *Reader) GetSamples(numSamples int) ([]Sample, error) {
 	if numSamples < 0 {
-		return nil, errors.New("numSamples cannot be negative")
+		return nil, ErrNegativeSampleCount
 	}
 	if uint32(numSamples) > r.numSamplesLeft {
-		return nil, errors.New("requested samples exceed remaining samples")
+		return nil, ErrNotEnoughSamples
 	}
 
+	containerBits, validBits, err := r.format.sampleLayout()
+	if err != nil {
+		return nil, err
+	}
 	samples := make([]Sample, numSamples)
-	bitsPerSample := int(r.format.BitsPerSample)
+	if err := r.readSamples(samples, containerBits, validBits); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// ReadSamples reads up to len(dst) sample frames into dst and returns the
+// number of frames read. Unlike GetSamples it does not allocate, so dst can be
+// reused across calls. Once every sample frame has been read it returns 0 and
+// io.EOF.
+func (r *Reader) ReadSamples(dst []Sample) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if r.numSamplesLeft == 0 {
+		return 0, io.EOF
+	}
+	containerBits, validBits, err := r.format.sampleLayout()
+	if err != nil {
+		return 0, err
+	}
+	n := min(len(dst), int(r.numSamplesLeft))
+	if err := r.readSamples(dst[:n], containerBits, validBits); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ReadInt16 reads up to len(dst)/NumChannels sample frames into dst as
+// interleaved 16-bit samples and returns the number of frames read. Samples
+// of other bit depths are scaled to the 16-bit range, dropping extra
+// precision, and 8-bit samples are converted to signed values. dst must
+// hold at least one sample frame. Like ReadSamples it does not allocate and
+// returns 0 and io.EOF once every sample frame has been read.
+func (r *Reader) ReadInt16(dst []int16) (int, error) {
+	return readInterleaved(r, dst, sampleScale.toInt16)
+}
+
+// ReadInt32 is like ReadInt16, but scales samples to the 32-bit range, which
+// holds every supported bit depth without loss.
+func (r *Reader) ReadInt32(dst []int32) (int, error) {
+	return readInterleaved(r, dst, sampleScale.toInt32)
+}
+
+// ReadFloat32 is like ReadInt16, but converts samples to floating point
+// values where -1 and 1 are full scale.
+func (r *Reader) ReadFloat32(dst []float32) (int, error) {
+	return readInterleaved(r, dst, sampleScale.toFloat32)
+}
+
+// readInterleaved reads sample frames into dst, converting each sample with
+// convert.
+func readInterleaved[T int16 | int32 | float32](r *Reader, dst []T, convert func(sampleScale, int) T) (int, error) {
 	numChannels := int(r.format.NumChannels)
-	originalSamplesLeft := r.numSamplesLeft
-
-	for i := 0; i < numSamples; i++ {
-		for ch := 0; ch < numChannels; ch++ {
-			var v int
-			switch bitsPerSample {
-			case 8:
-				v = int(r.br.ReadU8())
-			case 16:
-				v = int(int16(r.br.ReadU16(binary.LittleEndian)))
-			case 24:
-				v = int(r.br.ReadU24(binary.LittleEndian))
-			case 32:
-				v = int(r.br.ReadU32(binary.LittleEndian))
-			default:
-				return nil, ErrUnsupportedBitsPerSample
-			}
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if len(dst) < numChannels {
+		return 0, io.ErrShortBuffer
+	}
+	if r.numSamplesLeft == 0 {
+		return 0, io.EOF
+	}
+	containerBits, validBits, err := r.format.sampleLayout()
+	if err != nil {
+		return 0, err
+	}
+	var (
+		scale     = newSampleScale(validBits)
+		frameSize = numChannels * containerBits / 8
+		frames    = min(len(dst)/numChannels, int(r.numSamplesLeft))
+	)
+	if cap(r.values) < min(frames, sampleBlockFrames)*numChannels {
+		r.values = make([]int, min(frames, sampleBlockFrames)*numChannels)
+	}
+	err = r.readBlocks(frames, frameSize, func(block []byte, frame int) {
+		values := r.values[:len(block)/frameSize*numChannels]
+		decodeInterleaved(values, block, containerBits, containerBits-validBits)
+		out := dst[frame*numChannels:]
+		for i, v := range values {
+			out[i] = convert(scale, v)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return frames, nil
+}
 
-			if r.br.Err() != nil {
-				r.numSamplesLeft = originalSamplesLeft
-				return nil, r.br.Err()
-			}
+// readSamples decodes len(samples) sample frames into samples.
+func (r *Reader) readSamples(samples []Sample, containerBits, validBits int) error {
+	var (
+		numChannels = int(r.format.NumChannels)
+		frameSize   = numChannels * containerBits / 8
+	)
+	if numChannels > len(Sample{}) {
+		return fmt.Errorf("%w: %d channels", ErrTooManyChannels, numChannels)
+	}
+	return r.readBlocks(len(samples), frameSize, func(block []byte, frame int) {
+		decodeSamples(samples[frame:frame+len(block)/frameSize], block, numChannels, containerBits, containerBits-validBits)
+	})
+}
 
-			samples[i][ch] = v
+// readBlocks reads numFrames sample frames of frameSize bytes, one block of at
+// most sampleBlockFrames frames at a time, and passes each block together
+// with the index of its first frame to decode.
+func (r *Reader) readBlocks(numFrames, frameSize int, decode func(block []byte, frame int)) error {
+	for frame := 0; frame < numFrames; {
+		n := min(numFrames-frame, sampleBlockFrames)
+		if cap(r.buf) < n*frameSize {
+			r.buf = make([]byte, n*frameSize)
+		}
+		block := r.buf[:n*frameSize]
+		r.br.ReadFull(block)
+		if err := r.br.Err(); err != nil {
+			return readError(r.findChunk(riff.DATAChunkID), err)
 		}
-		r.numSamplesLeft -= 1
+		decode(block, frame)
+		r.numSamplesLeft -= uint32(n)
+		frame += n
 	}
-	return samples, nil
+	return nil
 }
 
 func parseFormatChunkData(fmtChunk *riff.Chunk) (Format, error) {
@@ -158,21 +662,30 @@ func parseFormatChunkData(fmtChunk *riff.Chunk) (Format, error) {
 		BitsPerSample: br.ReadU16(binary.LittleEndian),
 	}
 	if br.Err() != nil {
-		return Format{}, br.Err()
+		return Format{}, invalidChunk(riff.FMTChunkID, "too short")
+	}
+	// WAVE_FORMAT_EXTENSIBLE carries cbSize, valid bits, channel mask and
+	// the SubFormat GUID, whose first two bytes are the codec.
+	if format.AudioFormat == AudioFormatExtensible && len(fmtChunk.Data) >= 40 {
+		_ = br.ReadU16(binary.LittleEndian) // cbSize
+		format.ValidBitsPerSample = br.ReadU16(binary.LittleEndian)
+		format.ChannelMask = br.ReadU32(binary.LittleEndian)
+		format.SubFormat = br.ReadU16(binary.LittleEndian)
+		if br.Err() != nil {
+			return Format{}, invalidChunk(riff.FMTChunkID, "too short")
+		}
 	}
 
-	// Validate format fields
+	// Validate format fields. BlockAlign is checked by validateFields, after
+	// Load or Repair had the chance to correct it.
 	if format.NumChannels == 0 {
-		return Format{}, errors.New("invalid NumChannels: must be greater than 0")
+		return Format{}, &FormatError{Field: "NumChannels", Reason: "must be greater than 0"}
 	}
 	if format.SampleRate == 0 {
-		return Format{}, errors.New("invalid SampleRate: must be greater than 0")
-	}
-	if format.BlockAlign == 0 {
-		return Format{}, errors.New("invalid BlockAlign: must be greater than 0")
+		return Format{}, &FormatError{Field: "SampleRate", Reason: "must be greater than 0"}
 	}
 	if format.BitsPerSample == 0 {
-		return Format{}, errors.New("invalid BitsPerSample: must be greater than 0")
+		return Format{}, &FormatError{Field: "BitsPerSample", Reason: "must be greater than 0"}
 	}
 
 	return format, nil
@@ -1,10 +1,17 @@
 package wavgo
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/takurooo/wavgo/internal/binio"
 	"github.com/takurooo/wavgo/internal/riff"
 )
 
@@ -71,6 +78,7 @@ func TestReaderGetSamplesValidation(t *testing.T) {
 	// Test negative numSamples
 	samples, err := r.GetSamples(-1)
 	require.Nil(t, samples)
+	require.ErrorIs(t, err, ErrNegativeSampleCount)
 	require.EqualError(t, err, "numSamples cannot be negative")
 
 	// Test requesting more samples than available
@@ -162,7 +170,11 @@ func TestReaderFormatValidation(t *testing.T) {
 			},
 		}
 
-		_, err := parseFormatChunkData(mockChunk)
+		// A zero BlockAlign can be corrected, so it is only rejected by
+		// validateFields.
+		format, err := parseFormatChunkData(mockChunk)
+		require.NoError(t, err)
+		err = format.validateFields()
 		require.Error(t, err)
 		require.EqualError(t, err, "invalid BlockAlign: must be greater than 0")
 	})
@@ -220,3 +232,529 @@ func TestReaderGetNumSamplesAndLeft(t *testing.T) {
 	require.Equal(t, uint32(2), r.GetNumSamples())
 	require.Equal(t, uint32(0), r.GetNumSamplesLeft())
 }
+
+func TestReaderValidBits(t *testing.T) {
+	t.Run("12In16", func(t *testing.T) {
+		fmtData := pcm16FmtData(1, 8000)
+		fmtData[14] = 12                       // BitsPerSample
+		data := []byte{0x10, 0x00, 0xF0, 0xFF} // 1<<4, -1<<4
+		r := &Reader{src: bytes.NewReader(buildWAV(testChunk{"fmt ", fmtData}, testChunk{"data", data}))}
+		require.NoError(t, r.Load())
+
+		samples, err := r.GetSamples(2)
+		require.NoError(t, err)
+		require.Equal(t, []Sample{{1, 0}, {-1, 0}}, samples)
+	})
+
+	t.Run("20In24", func(t *testing.T) {
+		fmtData := pcm16FmtData(1, 48000)
+		binary.LittleEndian.PutUint32(fmtData[8:], 144000) // ByteRate
+		fmtData[12] = 3                                    // BlockAlign
+		fmtData[14] = 20                                   // BitsPerSample
+		data := []byte{
+			0xF0, 0xFF, 0x7F, // 0x7FFFF << 4
+			0x00, 0x00, 0x80, // -0x80000 << 4
+		}
+		r := &Reader{src: bytes.NewReader(buildWAV(testChunk{"fmt ", fmtData}, testChunk{"data", data}))}
+		require.NoError(t, r.Load())
+
+		samples, err := r.GetSamples(2)
+		require.NoError(t, err)
+		require.Equal(t, []Sample{{0x7FFFF, 0}, {-0x80000, 0}}, samples)
+	})
+
+	t.Run("24In32Extensible", func(t *testing.T) {
+		fmtData := &bytes.Buffer{}
+		binary.Write(fmtData, binary.LittleEndian, uint16(AudioFormatExtensible))
+		binary.Write(fmtData, binary.LittleEndian, uint16(1))      // NumChannels
+		binary.Write(fmtData, binary.LittleEndian, uint32(48000))  // SampleRate
+		binary.Write(fmtData, binary.LittleEndian, uint32(192000)) // ByteRate
+		binary.Write(fmtData, binary.LittleEndian, uint16(4))      // BlockAlign
+		binary.Write(fmtData, binary.LittleEndian, uint16(32))     // BitsPerSample
+		binary.Write(fmtData, binary.LittleEndian, uint16(22))     // cbSize
+		binary.Write(fmtData, binary.LittleEndian, uint16(24))     // ValidBitsPerSample
+		binary.Write(fmtData, binary.LittleEndian, uint32(0x4))    // ChannelMask
+		binary.Write(fmtData, binary.LittleEndian, uint16(AudioFormatPCM))
+		fmtData.Write(ksDataFormatSubtypeTail)
+		data := []byte{0x00, 0xFE, 0xFF, 0xFF} // -2 << 8
+
+		r := &Reader{src: bytes.NewReader(buildWAV(testChunk{"fmt ", fmtData.Bytes()}, testChunk{"data", data}))}
+		require.NoError(t, r.Load())
+
+		format := r.GetFormat()
+		require.Equal(t, uint16(24), format.ValidBitsPerSample)
+		require.Equal(t, uint32(0x4), format.ChannelMask)
+		require.Equal(t, uint16(AudioFormatPCM), format.SubFormat)
+		samples, err := r.GetSamples(1)
+		require.NoError(t, err)
+		require.Equal(t, []Sample{{-2, 0}}, samples)
+	})
+}
+
+func TestReaderLenient(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   2,
+		SampleRate:    8000,
+		ByteRate:      32000,
+		BlockAlign:    4,
+		BitsPerSample: 16,
+	}
+	filename := "testdata/TestReaderLenient.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetInfo(&Info{Title: "take 1"})
+	require.NoError(t, w.WriteSamples([]Sample{{1, -1}, {2, -2}, {3, -3}}))
+	require.NoError(t, w.Close())
+
+	// Simulate a recording interrupted before the header sizes were written
+	// and in the middle of a sample frame.
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint32(b[4:8], 0)
+	binary.LittleEndian.PutUint32(b[len(b)-12-4:], 0)
+	b = b[:len(b)-2]
+	require.NoError(t, os.WriteFile(filename, b, 0644))
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.Error(t, r.Load())
+
+	r = NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	r.SetLenient(true)
+	require.NoError(t, r.Load())
+	require.Len(t, r.GetWarnings(), 3)
+	require.Equal(t, &Info{Title: "take 1"}, r.GetInfo())
+	require.Equal(t, uint32(2), r.GetNumSamples())
+	samples, err := r.GetSamples(2)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{1, -1}, {2, -2}}, samples)
+}
+
+func TestReaderLenientMetadata(t *testing.T) {
+	r := &Reader{src: bytes.NewReader(buildWAV(
+		testChunk{"fmt ", pcm16FmtData(1, 8000)},
+		testChunk{"bext", []byte{0x00, 0x00}},
+		testChunk{"smpl", []byte{0x00, 0x00}},
+		testChunk{"acid", make([]byte, 24)},
+		testChunk{"data", []byte{0x01, 0x00}},
+	))}
+	r.SetLenient(true)
+	require.NoError(t, r.Load())
+	require.Equal(t, []string{
+		"ignored metadata: invalid bext chunk: too short at offset 36",
+		"ignored metadata: invalid smpl chunk: too short at offset 46",
+	}, r.GetWarnings())
+	require.NotNil(t, r.GetACID())
+}
+
+func TestReaderLenientWellFormed(t *testing.T) {
+	r := &Reader{src: bytes.NewReader(buildWAV(testChunk{"fmt ", pcm16FmtData(1, 8000)}, testChunk{"data", []byte{0x01, 0x00}}))}
+	r.SetLenient(true)
+	require.NoError(t, r.Load())
+	require.Nil(t, r.GetWarnings())
+}
+
+func TestReaderCorrectFormat(t *testing.T) {
+	fmtData := pcm16FmtData(2, 48000)
+	binary.LittleEndian.PutUint32(fmtData[8:], 128000) // ByteRate
+	binary.LittleEndian.PutUint16(fmtData[12:], 3)     // BlockAlign
+	input := buildWAV(testChunk{"fmt ", fmtData}, testChunk{"data", []byte{0x01, 0x00, 0x02, 0x00}})
+
+	r := &Reader{src: bytes.NewReader(input)}
+	err := r.Load()
+	var formatErr *FormatError
+	require.True(t, errors.As(err, &formatErr))
+	require.Equal(t, "BlockAlign", formatErr.Field)
+
+	r = &Reader{src: bytes.NewReader(input)}
+	r.SetCorrectFormat(true)
+	require.NoError(t, r.Load())
+	require.Equal(t, []string{"BlockAlign: 3 -> 4", "ByteRate: 128000 -> 192000"}, r.GetWarnings())
+	format := r.GetFormat()
+	require.Equal(t, uint16(4), format.BlockAlign)
+	require.Equal(t, uint32(192000), format.ByteRate)
+	samples, err := r.GetSamples(1)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{1, 2}}, samples)
+}
+
+func TestReaderCorrectZeroBlockAlign(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zero_block_align.wav")
+	w := NewWriter(NewPCMFormat(2, 48000, 16))
+	require.NoError(t, w.Open(path))
+	require.NoError(t, w.WriteSamples([]Sample{{1, -1}, {2, -2}}))
+	require.NoError(t, w.Close())
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint16(b[12+8+12:], 0) // BlockAlign
+	require.NoError(t, os.WriteFile(path, b, 0644))
+
+	load := func(t *testing.T, configure func(r *Reader)) (*Reader, error) {
+		r := NewReader()
+		require.NoError(t, r.Open(path))
+		t.Cleanup(func() { r.Close() })
+		configure(r)
+		return r, r.Load()
+	}
+
+	_, err = load(t, func(r *Reader) {})
+	require.EqualError(t, err, "invalid BlockAlign: must be greater than 0")
+
+	for name, configure := range map[string]func(r *Reader){
+		"CorrectFormat": func(r *Reader) { r.SetCorrectFormat(true) },
+		"Lenient":       func(r *Reader) { r.SetLenient(true) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := load(t, configure)
+			require.NoError(t, err)
+			require.Equal(t, []string{"BlockAlign: 0 -> 4"}, r.GetWarnings())
+			samples, err := r.GetSamples(2)
+			require.NoError(t, err)
+			require.Equal(t, []Sample{{1, -1}, {2, -2}}, samples)
+		})
+	}
+}
+
+func TestReaderParseError(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		err     error
+		chunkID string
+		offset  int64
+		message string
+	}{
+		{
+			name:    "NotRIFF",
+			input:   buildRIFF("RIFX", "WAVE"),
+			err:     ErrNotRIFF,
+			offset:  0,
+			message: "not found riff chunk at offset 0",
+		},
+		{
+			name:    "MissingData",
+			input:   buildWAV(testChunk{"fmt ", pcm16FmtData(1, 8000)}),
+			err:     ErrChunkNotFound,
+			chunkID: "data",
+			offset:  -1,
+			message: "not found DataChunk",
+		},
+		{
+			name:    "ShortFmt",
+			input:   buildWAV(testChunk{"fmt ", []byte{0x01, 0x00}}, testChunk{"data", nil}),
+			err:     ErrInvalidChunk,
+			chunkID: "fmt ",
+			offset:  12,
+			message: "invalid fmt chunk: too short at offset 12",
+		},
+		{
+			name:    "Truncated",
+			input:   buildWAV(testChunk{"fmt ", pcm16FmtData(1, 8000)}, testChunk{"data", []byte{0x01, 0x00}})[:40],
+			err:     ErrTruncated,
+			chunkID: "data",
+			offset:  36,
+			message: "unexpected end of file at offset 36",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reader{src: bytes.NewReader(tt.input)}
+			err := r.Load()
+			require.ErrorIs(t, err, tt.err)
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, tt.chunkID, parseErr.ChunkID)
+			require.Equal(t, tt.offset, parseErr.Offset)
+			require.EqualError(t, err, tt.message)
+		})
+	}
+}
+
+func TestReaderMetadataErrors(t *testing.T) {
+	id3v22 := []byte{'I', 'D', '3', 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	r := &Reader{src: bytes.NewReader(buildWAV(
+		testChunk{"fmt ", pcm16FmtData(1, 8000)},
+		testChunk{"bext", []byte{0x00, 0x00}},
+		testChunk{"id3 ", id3v22},
+		testChunk{"smpl", make([]byte, 36)},
+		testChunk{"data", []byte{0x01, 0x00}},
+	))}
+	require.NoError(t, r.Load())
+	require.Nil(t, r.GetBroadcastExtension())
+	require.Nil(t, r.GetID3())
+	require.Nil(t, r.GetInfo())
+	require.NotNil(t, r.GetSampler())
+
+	errs := r.GetMetadataErrors()
+	require.Len(t, errs, 2)
+	var parseErr *ParseError
+	require.ErrorAs(t, errs[0], &parseErr)
+	require.ErrorIs(t, errs[0], ErrInvalidChunk)
+	require.Equal(t, "bext", parseErr.ChunkID)
+	require.Equal(t, int64(36), parseErr.Offset)
+	require.EqualError(t, errs[1], "unsupported ID3 version at offset 46")
+}
+
+func TestReaderListParseErrorOffset(t *testing.T) {
+	list := append([]byte("INFOINAM"), 0x10, 0x00, 0x00, 0x00, 'a', 0x00)
+	r := &Reader{src: bytes.NewReader(buildWAV(
+		testChunk{"fmt ", pcm16FmtData(1, 8000)},
+		testChunk{"LIST", list},
+		testChunk{"data", []byte{0x01, 0x00}},
+	))}
+	require.NoError(t, r.Load())
+	require.Nil(t, r.GetInfo())
+
+	errs := r.GetMetadataErrors()
+	require.Len(t, errs, 1)
+	var parseErr *ParseError
+	require.ErrorAs(t, errs[0], &parseErr)
+	require.ErrorIs(t, errs[0], ErrInvalidChunkSize)
+	require.Equal(t, "INAM", parseErr.ChunkID)
+	require.Equal(t, int64(48), parseErr.Offset)
+}
+
+func TestReaderMetadataErrorLocation(t *testing.T) {
+	t.Run("ID3", func(t *testing.T) {
+		r := &Reader{src: bytes.NewReader(buildWAV(
+			testChunk{"fmt ", pcm16FmtData(1, 8000)},
+			testChunk{"ID3 ", []byte{'I', 'D', '3', 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+			testChunk{"data", []byte{0x01, 0x00}},
+		))}
+		require.NoError(t, r.Load())
+		var parseErr *ParseError
+		require.ErrorAs(t, r.GetMetadataErrors()[0], &parseErr)
+		require.Equal(t, "ID3 ", parseErr.ChunkID)
+		require.Equal(t, int64(36), parseErr.Offset)
+	})
+
+	t.Run("adtl", func(t *testing.T) {
+		cueData, _ := encodeCuePoints([]CuePoint{{ID: 1}})
+		r := &Reader{src: bytes.NewReader(buildWAV(
+			testChunk{"fmt ", pcm16FmtData(1, 8000)},
+			testChunk{"cue ", cueData},
+			testChunk{"LIST", append([]byte("adtllabl"), 0x02, 0x00, 0x00, 0x00, 0x01, 0x00)},
+			testChunk{"data", []byte{0x01, 0x00}},
+		))}
+		require.NoError(t, r.Load())
+		var parseErr *ParseError
+		require.ErrorAs(t, r.GetMetadataErrors()[0], &parseErr)
+		require.Equal(t, "labl", parseErr.ChunkID)
+		require.Equal(t, int64(84), parseErr.Offset)
+	})
+}
+
+func TestReaderTruncatedSamples(t *testing.T) {
+	r := &Reader{src: bytes.NewReader(buildWAV(testChunk{"fmt ", pcm16FmtData(1, 8000)}, testChunk{"data", []byte{0x01, 0x00, 0x02, 0x00}}))}
+	require.NoError(t, r.Load())
+	// Shorten the sample data behind the Reader's back.
+	r.br = binio.NewReader(bytes.NewReader([]byte{0x01, 0x00, 0x02}))
+	_, err := r.GetSamples(2)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.ErrorIs(t, err, ErrTruncated)
+	require.Equal(t, "data", parseErr.ChunkID)
+	require.Equal(t, int64(36), parseErr.Offset)
+}
+
+func TestReaderErrors(t *testing.T) {
+	require.ErrorIs(t, NewReader().Load(), ErrNotOpen)
+
+	r := &Reader{src: bytes.NewReader(buildWAV(testChunk{"fmt ", pcm16FmtData(1, 8000)}, testChunk{"data", []byte{0x01, 0x00}}))}
+	require.NoError(t, r.Load())
+	_, err := r.GetSamples(2)
+	require.ErrorIs(t, err, ErrNotEnoughSamples)
+}
+
+func TestReaderReadSamples(t *testing.T) {
+	data := []byte{
+		0x01, 0x00, 0xFF, 0xFF, // {1, -1}
+		0xFF, 0x7F, 0x00, 0x80, // {32767, -32768}
+		0x64, 0x00, 0xC8, 0x00, // {100, 200}
+	}
+	newReader := func(t *testing.T) *Reader {
+		r := &Reader{src: bytes.NewReader(buildWAV(testChunk{"fmt ", pcm16FmtData(2, 8000)}, testChunk{"data", data}))}
+		require.NoError(t, r.Load())
+		return r
+	}
+
+	t.Run("Samples", func(t *testing.T) {
+		r := newReader(t)
+		dst := make([]Sample, 2)
+		n, err := r.ReadSamples(dst)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Equal(t, []Sample{{1, -1}, {32767, -32768}}, dst)
+
+		n, err = r.ReadSamples(dst)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, Sample{100, 200}, dst[0])
+
+		n, err = r.ReadSamples(dst)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, 0, n)
+	})
+
+	t.Run("Int16", func(t *testing.T) {
+		r := newReader(t)
+		dst := make([]int16, 7) // the odd sample is left untouched
+		n, err := r.ReadInt16(dst)
+		require.NoError(t, err)
+		require.Equal(t, 3, n)
+		require.Equal(t, []int16{1, -1, 32767, -32768, 100, 200, 0}, dst)
+		require.Equal(t, uint32(0), r.GetNumSamplesLeft())
+
+		n, err = r.ReadInt16(dst)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, 0, n)
+	})
+
+	t.Run("Int32", func(t *testing.T) {
+		r := newReader(t)
+		dst := make([]int32, 2)
+		n, err := r.ReadInt32(dst)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, []int32{1 << 16, -1 << 16}, dst)
+		require.Equal(t, uint32(2), r.GetNumSamplesLeft())
+	})
+
+	t.Run("Float32", func(t *testing.T) {
+		r := newReader(t)
+		r.ReadSamples(make([]Sample, 1))
+		dst := make([]float32, 2)
+		n, err := r.ReadFloat32(dst)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, []float32{32767.0 / 32768, -1}, dst)
+	})
+
+	t.Run("ShortBuffer", func(t *testing.T) {
+		r := newReader(t)
+		n, err := r.ReadInt16(make([]int16, 1))
+		require.ErrorIs(t, err, io.ErrShortBuffer)
+		require.Equal(t, 0, n)
+
+		n, err = r.ReadInt16(nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, n)
+	})
+}
+
+func TestReaderReadAllocs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allocs.wav")
+	format := NewPCMFormat(2, 48000, 24)
+	w := NewWriter(format)
+	require.NoError(t, w.Open(path))
+	require.NoError(t, w.WriteSamples(make([]Sample, 1<<16)))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(path))
+	defer r.Close()
+	require.NoError(t, r.Load())
+
+	samples := make([]Sample, 256)
+	int16s := make([]int16, 512)
+	int32s := make([]int32, 512)
+	float32s := make([]float32, 512)
+	var (
+		runs   int
+		frames int
+		err    error
+	)
+	check := func(n int, e error) {
+		frames += n
+		if err == nil {
+			err = e
+		}
+	}
+	allocs := testing.AllocsPerRun(50, func() {
+		runs++
+		check(r.ReadSamples(samples))
+		check(r.ReadInt16(int16s))
+		check(r.ReadInt32(int32s))
+		check(r.ReadFloat32(float32s))
+	})
+	require.NoError(t, err)
+	require.Equal(t, runs*4*256, frames)
+	require.Zero(t, allocs)
+}
+
+// benchmarkReader returns a loaded Reader of an in-memory file holding
+// numFrames sample frames of format.
+func benchmarkReader(b *testing.B, format *Format, numFrames int) *Reader {
+	path := filepath.Join(b.TempDir(), "bench.wav")
+	w := NewWriter(format)
+	require.NoError(b, w.Open(path))
+	require.NoError(b, w.WriteSamples(benchmarkSamples(format, numFrames)))
+	require.NoError(b, w.Close())
+	data, err := os.ReadFile(path)
+	require.NoError(b, err)
+
+	r := &Reader{src: bytes.NewReader(data)}
+	require.NoError(b, r.Load())
+	return r
+}
+
+// benchmarkRead calls read, which reads blockFrames sample frames, b.N times,
+// rewinding the Reader whenever fewer frames are left.
+func benchmarkRead(b *testing.B, format *Format, read func(r *Reader) error) {
+	const blockFrames = 1024
+	r := benchmarkReader(b, format, 64*blockFrames)
+	b.SetBytes(blockFrames * int64(format.BlockAlign))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if r.GetNumSamplesLeft() < blockFrames {
+			b.StopTimer()
+			require.NoError(b, r.Load())
+			b.StartTimer()
+		}
+		if err := read(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReaderGetSamples(b *testing.B) {
+	for _, bf := range benchmarkFormats {
+		b.Run(bf.name, func(b *testing.B) {
+			benchmarkRead(b, bf.format, func(r *Reader) error {
+				_, err := r.GetSamples(1024)
+				return err
+			})
+		})
+	}
+}
+
+func BenchmarkReaderRead(b *testing.B) {
+	var (
+		samples  = make([]Sample, 1024)
+		int16s   = make([]int16, 2*1024)
+		int32s   = make([]int32, 2*1024)
+		float32s = make([]float32, 2*1024)
+	)
+	reads := []struct {
+		name string
+		read func(r *Reader) error
+	}{
+		{"Samples", func(r *Reader) error { _, err := r.ReadSamples(samples); return err }},
+		{"Int16", func(r *Reader) error { _, err := r.ReadInt16(int16s); return err }},
+		{"Int32", func(r *Reader) error { _, err := r.ReadInt32(int32s); return err }},
+		{"Float32", func(r *Reader) error { _, err := r.ReadFloat32(float32s); return err }},
+	}
+	for _, rd := range reads {
+		for _, bf := range benchmarkFormats {
+			b.Run(rd.name+"/"+bf.name, func(b *testing.B) {
+				benchmarkRead(b, bf.format, rd.read)
+			})
+		}
+	}
+}
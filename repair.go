@@ -0,0 +1,187 @@
+package wavgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/takurooo/wavgo/internal/binio"
+	"github.com/takurooo/wavgo/internal/riff"
+)
+
+// RepairReport describes the changes Repair made to a file.
+type RepairReport struct {
+	// Fixes describes every change written to the file, in order.
+	Fixes []string
+}
+
+// Repaired reports whether Repair changed the file.
+func (r *RepairReport) Repaired() bool {
+	return len(r.Fixes) != 0
+}
+
+// String returns the fixes one per line, or "no repairs needed".
+func (r *RepairReport) String() string {
+	if !r.Repaired() {
+		return "no repairs needed"
+	}
+	return strings.Join(r.Fixes, "\n")
+}
+
+func (r *RepairReport) fixf(format string, args ...any) {
+	r.Fixes = append(r.Fixes, fmt.Sprintf(format, args...))
+}
+
+// Repair fixes the headers of the RIFF/WAVE file at filePath in place,
+// typically after a recording was interrupted before the Writer was closed.
+// The file is parsed leniently (see Reader.SetLenient) and then:
+//
+//   - an inconsistent BlockAlign or ByteRate in the fmt chunk is recomputed
+//     from the channel count, bit depth and sample rate
+//   - a data chunk that is the last chunk is extended to the end of the
+//     file, as samples may have been written after its size was
+//   - a partial sample frame at the end of such a data chunk is trimmed
+//   - bytes after a last chunk other than data are removed
+//   - the data and RIFF sizes are recomputed from the actual file length
+//
+// Sample data is never rewritten. The returned report lists every change;
+// a well-formed file is left untouched.
+func Repair(filePath string) (*RepairReport, error) {
+	f, err := os.OpenFile(filePath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	report, err := repair(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if report.Repaired() {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func repair(f *os.File) (*RepairReport, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	fileSize := fi.Size()
+	rc, err := riff.ScanRIFFChunkWithOptions(f, riff.ReadOptions{Lenient: true, Size: fileSize})
+	if err != nil {
+		return nil, err
+	}
+	if rc.ID != riff.RIFFChunkID {
+		return nil, fmt.Errorf("%w: repair of %s files", ErrUnsupportedContainer, rc.ID)
+	}
+	fmtChunk, err := rc.GetFMTChunk()
+	if err != nil {
+		return nil, err
+	}
+	dataChunk, err := rc.GetDataChunk()
+	if err != nil {
+		return nil, err
+	}
+	fmtChunk.Data = make([]byte, fmtChunk.Length)
+	if _, err := f.ReadAt(fmtChunk.Data, fmtChunk.Offset+8); err != nil {
+		return nil, err
+	}
+	format, err := parseFormatChunkData(fmtChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RepairReport{}
+	bw := binio.NewWriter(f)
+	// ----------------------------
+	// Format Chunk
+	// ----------------------------
+	original := format
+	report.Fixes = append(report.Fixes, format.correct()...)
+	if err := format.validateFields(); err != nil {
+		return nil, err
+	}
+	if format.BlockAlign != original.BlockAlign {
+		bw.SetOffset(fmtChunk.Offset + 8 + 12)
+		bw.WriteU16(format.BlockAlign, binary.LittleEndian)
+	}
+	if format.ByteRate != original.ByteRate {
+		bw.SetOffset(fmtChunk.Offset + 8 + 8)
+		bw.WriteU32(format.ByteRate, binary.LittleEndian)
+	}
+	// ----------------------------
+	// Data Chunk
+	// ----------------------------
+	last := rc.SubChunks[len(rc.SubChunks)-1]
+	contentEnd := last.Offset + 8 + last.Length
+	trailing := fileSize - contentEnd - (last.Length & 1)
+	dataSize := dataChunk.Length
+	end := contentEnd
+	if dataChunk == last {
+		// Samples written after the last header update, as by a recording
+		// with checkpoints, follow the data chunk: extend it to the end of
+		// the file instead of removing them.
+		if trailing > 0 {
+			dataSize = fileSize - dataChunk.Offset - 8
+		}
+		if n := dataSize % int64(format.BlockAlign); n != 0 {
+			dataSize -= n
+			report.fixf("trimmed a partial sample frame of %d bytes", n)
+		}
+		end = dataChunk.Offset + 8 + dataSize
+	} else if trailing > 0 {
+		report.fixf("removed %d bytes after the last chunk", trailing)
+	}
+	pad := end & 1
+	end += pad
+	if end-8 > math.MaxUint32 {
+		return nil, fmt.Errorf("cannot repair RIFF size: %w", ErrFileTooLarge)
+	}
+	if int64(dataChunk.Size) != dataSize {
+		report.fixf("data size: %d -> %d", dataChunk.Size, dataSize)
+		bw.SetOffset(dataChunk.Offset + 4)
+		bw.WriteU32(uint32(dataSize), binary.LittleEndian)
+	}
+	// ----------------------------
+	// RIFF Chunk
+	// ----------------------------
+	if pad != 0 && end <= fileSize {
+		// The byte may be left over from a trimmed sample frame.
+		b := []byte{0}
+		if _, err := f.ReadAt(b, end-1); err != nil {
+			return nil, err
+		}
+		if b[0] != 0 {
+			report.fixf("cleared the pad byte at offset %d", end-1)
+			bw.SetOffset(end - 1)
+			bw.WriteU8(0)
+		}
+	}
+	if bw.Err() != nil {
+		return nil, bw.Err()
+	}
+	if fileSize != end {
+		// Truncating also appends a missing pad byte after the last chunk.
+		if err := f.Truncate(end); err != nil {
+			return nil, err
+		}
+	}
+	if riffSize := uint32(end - 8); rc.Size != riffSize {
+		report.fixf("RIFF size: %d -> %d", rc.Size, riffSize)
+		bw.SetOffset(4)
+		bw.WriteU32(riffSize, binary.LittleEndian)
+	}
+	if bw.Err() != nil {
+		return nil, bw.Err()
+	}
+	return report, nil
+}
@@ -0,0 +1,168 @@
+package wavgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeRepairTestFile(t *testing.T, filename string, afterData ...testChunk) []byte {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   2,
+		SampleRate:    8000,
+		ByteRate:      32000,
+		BlockAlign:    4,
+		BitsPerSample: 16,
+	}
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	w.SetInfo(&Info{Title: "take 2"})
+	for _, c := range afterData {
+		require.NoError(t, w.AddChunk(c.id, c.data, AfterData))
+	}
+	require.NoError(t, w.WriteSamples([]Sample{{1, -1}, {2, -2}, {3, -3}}))
+	require.NoError(t, w.Close())
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	return b
+}
+
+func TestRepair(t *testing.T) {
+	filename := "testdata/TestRepair.wav"
+	defer os.Remove(filename)
+	want := writeRepairTestFile(t, filename)
+
+	// Zero the sizes as left by an interrupted recording, break the fmt
+	// chunk and cut the last sample frame in half.
+	b := append([]byte(nil), want...)
+	binary.LittleEndian.PutUint32(b[4:8], 0)
+	binary.LittleEndian.PutUint32(b[12+8+8:], 1)  // ByteRate
+	binary.LittleEndian.PutUint16(b[12+8+12:], 3) // BlockAlign
+	binary.LittleEndian.PutUint32(b[len(b)-12-4:], 0)
+	require.NoError(t, os.WriteFile(filename, b[:len(b)-2], 0644))
+
+	report, err := Repair(filename)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"BlockAlign: 3 -> 4",
+		"ByteRate: 1 -> 32000",
+		"trimmed a partial sample frame of 2 bytes",
+		"data size: 0 -> 8",
+		fmt.Sprintf("RIFF size: 0 -> %d", len(want)-8-4),
+	}, report.Fixes)
+
+	got, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Len(t, got, len(want)-4)
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, &Info{Title: "take 2"}, r.GetInfo())
+	samples, err := r.GetSamples(int(r.GetNumSamples()))
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{1, -1}, {2, -2}}, samples)
+
+	report, err = Repair(filename)
+	require.NoError(t, err)
+	require.False(t, report.Repaired())
+	require.Equal(t, "no repairs needed", report.String())
+}
+
+func TestRepairTrailingGarbage(t *testing.T) {
+	filename := "testdata/TestRepairTrailingGarbage.wav"
+	defer os.Remove(filename)
+	want := writeRepairTestFile(t, filename, testChunk{"xtra", []byte{0x01, 0x02}})
+	b := append(append([]byte(nil), want...), 0xFF, 0xFE, 0x00, 0x01, 0x02)
+	require.NoError(t, os.WriteFile(filename, b, 0644))
+
+	report, err := Repair(filename)
+	require.NoError(t, err)
+	require.Equal(t, []string{"removed 5 bytes after the last chunk"}, report.Fixes)
+	got, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestRepairStaleDataSize(t *testing.T) {
+	filename := "testdata/TestRepairStaleDataSize.wav"
+	defer os.Remove(filename)
+	want := writeRepairTestFile(t, filename)
+
+	// Leave the sizes of the last checkpoint, one sample frame ago, and
+	// append half a frame as left by a kill in the middle of a write.
+	b := append([]byte(nil), want...)
+	binary.LittleEndian.PutUint32(b[4:8], uint32(len(want)-8-4))
+	binary.LittleEndian.PutUint32(b[len(b)-12-4:], 8)
+	b = append(b, 0x04, 0x00)
+	require.NoError(t, os.WriteFile(filename, b, 0644))
+
+	report, err := Repair(filename)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"trimmed a partial sample frame of 2 bytes",
+		"data size: 8 -> 12",
+		fmt.Sprintf("RIFF size: %d -> %d", len(want)-8-4, len(want)-8),
+	}, report.Fixes)
+	got, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestRepairPadByte(t *testing.T) {
+	filename := "testdata/TestRepairPadByte.wav"
+	defer os.Remove(filename)
+	w := NewWriter(NewPCMFormat(1, 8000, 24))
+	require.NoError(t, w.Open(filename))
+	require.NoError(t, w.WriteSamples([]Sample{{0x123456}}))
+	require.NoError(t, w.Close())
+	want, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	// Replace the pad byte after the odd-sized data chunk by a partial
+	// sample frame.
+	b := append(append([]byte(nil), want[:len(want)-1]...), 0xAB, 0xCD)
+	require.NoError(t, os.WriteFile(filename, b, 0644))
+
+	report, err := Repair(filename)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"trimmed a partial sample frame of 2 bytes",
+		fmt.Sprintf("cleared the pad byte at offset %d", len(want)-1),
+	}, report.Fixes)
+	got, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestRepairZeroBlockAlign(t *testing.T) {
+	filename := "testdata/TestRepairZeroBlockAlign.wav"
+	defer os.Remove(filename)
+	want := writeRepairTestFile(t, filename)
+	b := append([]byte(nil), want...)
+	binary.LittleEndian.PutUint16(b[12+8+12:], 0) // BlockAlign
+	require.NoError(t, os.WriteFile(filename, b, 0644))
+
+	report, err := Repair(filename)
+	require.NoError(t, err)
+	require.Equal(t, []string{"BlockAlign: 0 -> 4"}, report.Fixes)
+	got, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestRepairError(t *testing.T) {
+	_, err := Repair("non/existent/file.wav")
+	require.Error(t, err)
+
+	filename := "testdata/TestRepairError.wav"
+	defer os.Remove(filename)
+	require.NoError(t, os.WriteFile(filename, buildWAV(testChunk{"fmt ", pcm16FmtData(1, 8000)}), 0644))
+	_, err = Repair(filename)
+	require.EqualError(t, err, "not found DataChunk")
+}
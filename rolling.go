@@ -0,0 +1,246 @@
+package wavgo
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultRollingTimeLayout is the layout used for {time} in
+// RollingOptions.Template when TimeLayout is empty.
+const DefaultRollingTimeLayout = "20060102-150405"
+
+// RollingOptions configures a RollingWriter.
+type RollingOptions struct {
+	// Template is the path of the output files. "{seq}" is replaced with
+	// the sequence number of the file, starting at 1 and zero-padded to
+	// four digits, and "{time}" with the time of the first sample frame of
+	// the file formatted with TimeLayout. It must contain at least one of
+	// them, e.g. "capture-{time}-{seq}.wav". Existing files are never
+	// overwritten: if a path is taken, for example by a previous run or by
+	// two files starting within the resolution of TimeLayout, the suffix
+	// "-1", "-2", ... is added before its extension.
+	Template string
+
+	// TimeLayout is the time.Format layout used for {time}. Empty selects
+	// DefaultRollingTimeLayout.
+	TimeLayout string
+
+	// StartTime is the time of the first sample frame written. The time of
+	// every following file is derived from it and the number of frames
+	// written before, so file names carry no gaps or jitter. A zero
+	// StartTime selects the time of the first call to WriteSamples.
+	StartTime time.Time
+
+	// MaxFrames, MaxDuration and MaxBytes limit the size of each file; a
+	// new file is started as soon as one of them is reached. Zero disables
+	// a limit. MaxDuration is converted to sample frames and MaxBytes counts
+	// the header and the sample data, but not chunks written after the data
+	// chunk at Close. Files never exceed the 4 GiB RIFF limit.
+	MaxFrames   uint64
+	MaxDuration time.Duration
+	MaxBytes    int64
+
+	// BroadcastExtension, if set, is written to every file with its
+	// TimeReference advanced by the number of sample frames written to the
+	// previous files, so that each file carries the time of its own first
+	// sample.
+	BroadcastExtension *BroadcastExtension
+
+	// Atomic writes every file with Writer.OpenAtomic.
+	Atomic bool
+
+	// Setup is called with the Writer of every new file before it is
+	// opened, e.g. to set metadata or enable checkpoints.
+	Setup func(w *Writer) error
+}
+
+// RollingWriter writes a continuous stream of samples to a sequence of WAV
+// files, starting a new file whenever a size or duration limit is reached.
+// Every sample frame is written to exactly one file and files are split on
+// frame boundaries, so concatenating the files reproduces the stream.
+type RollingWriter struct {
+	format      *Format
+	opts        RollingOptions
+	w           *Writer
+	seq         int
+	start       time.Time
+	fileFrames  uint64
+	maxFrames   uint64
+	totalFrames uint64
+	paths       []string
+}
+
+// NewRollingWriter creates a RollingWriter writing files of the given
+// format. The first file is created on the first call to WriteSamples.
+func NewRollingWriter(format *Format, opts *RollingOptions) (*RollingWriter, error) {
+	if opts == nil || !strings.Contains(opts.Template, "{seq}") && !strings.Contains(opts.Template, "{time}") {
+		return nil, ErrInvalidTemplate
+	}
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	rw := &RollingWriter{format: format, opts: *opts, start: opts.StartTime}
+	if rw.opts.TimeLayout == "" {
+		rw.opts.TimeLayout = DefaultRollingTimeLayout
+	}
+	return rw, nil
+}
+
+// WriteSamples writes samples to the current file, closing it and
+// continuing in a new file whenever a limit is reached.
+func (rw *RollingWriter) WriteSamples(samples []Sample) error {
+	for len(samples) > 0 {
+		if rw.w == nil {
+			if err := rw.openNext(); err != nil {
+				return err
+			}
+		}
+		n := uint64(len(samples))
+		if room := rw.maxFrames - rw.fileFrames; n > room {
+			n = room
+		}
+		if err := rw.w.WriteSamples(samples[:n]); err != nil {
+			return err
+		}
+		samples = samples[n:]
+		rw.fileFrames += n
+		rw.totalFrames += n
+		if rw.fileFrames == rw.maxFrames {
+			if err := rw.closeCurrent(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes the current file.
+func (rw *RollingWriter) Close() error {
+	if rw.w == nil {
+		return nil
+	}
+	return rw.closeCurrent()
+}
+
+// GetPaths returns the paths of the files created so far, in order.
+func (rw *RollingWriter) GetPaths() []string {
+	return rw.paths
+}
+
+// openNext creates the next file and computes how many frames it can hold.
+func (rw *RollingWriter) openNext() error {
+	if rw.start.IsZero() {
+		rw.start = time.Now()
+	}
+	rw.seq++
+	path := strings.NewReplacer(
+		"{seq}", fmt.Sprintf("%04d", rw.seq),
+		"{time}", rw.frameTime(rw.totalFrames).Format(rw.opts.TimeLayout),
+	).Replace(rw.opts.Template)
+
+	w := NewWriter(rw.format)
+	if rw.opts.BroadcastExtension != nil {
+		bext := *rw.opts.BroadcastExtension
+		bext.TimeReference += rw.totalFrames
+		w.SetBroadcastExtension(&bext)
+	}
+	if rw.opts.Setup != nil {
+		if err := rw.opts.Setup(w); err != nil {
+			return err
+		}
+	}
+	path, err := rw.create(w, path)
+	if err != nil {
+		return err
+	}
+	// Write the header to learn how much room is left for sample data.
+	if err := w.WriteSamples(nil); err != nil {
+		w.Abort()
+		return err
+	}
+	maxFrames, err := rw.fileLimit(w.bw.GetOffset())
+	if err != nil {
+		w.Abort()
+		return err
+	}
+	rw.w = w
+	rw.fileFrames = 0
+	rw.maxFrames = maxFrames
+	rw.paths = append(rw.paths, path)
+	return nil
+}
+
+// create opens w on path, or on path with a numeric suffix if a file
+// already exists there, and returns the path used.
+func (rw *RollingWriter) create(w *Writer, path string) (string, error) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := range 10000 {
+		name := path
+		if n > 0 {
+			name = fmt.Sprintf("%s-%d%s", base, n, ext)
+		}
+		if rw.opts.Atomic {
+			// The file only appears at Close, so only the paths that are
+			// taken now can be avoided.
+			if _, err := os.Lstat(name); !os.IsNotExist(err) {
+				if err != nil {
+					return "", err
+				}
+				continue
+			}
+			return name, w.OpenAtomic(name)
+		}
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		w.openFile(f, name)
+		return name, nil
+	}
+	return "", fmt.Errorf("cannot create a unique file for %s: %w", path, os.ErrExist)
+}
+
+// fileLimit returns the number of sample frames a file with a header of
+// headerSize bytes can hold.
+func (rw *RollingWriter) fileLimit(headerSize int64) (uint64, error) {
+	blockAlign := int64(rw.format.BlockAlign)
+	maxFrames := uint64((math.MaxUint32 - headerSize) / blockAlign)
+	if rw.opts.MaxFrames != 0 {
+		maxFrames = min(maxFrames, rw.opts.MaxFrames)
+	}
+	if rw.opts.MaxDuration != 0 {
+		sampleRate := uint64(rw.format.SampleRate)
+		seconds := uint64(rw.opts.MaxDuration / time.Second)
+		fraction := uint64(rw.opts.MaxDuration % time.Second)
+		maxFrames = min(maxFrames, seconds*sampleRate+fraction*sampleRate/uint64(time.Second))
+	}
+	if rw.opts.MaxBytes != 0 {
+		maxFrames = min(maxFrames, uint64(max((rw.opts.MaxBytes-headerSize)/blockAlign, 0)))
+	}
+	if maxFrames == 0 {
+		return 0, ErrLimitTooSmall
+	}
+	return maxFrames, nil
+}
+
+// frameTime returns the time of the given sample frame of the stream.
+func (rw *RollingWriter) frameTime(frame uint64) time.Time {
+	sampleRate := uint64(rw.format.SampleRate)
+	seconds := frame / sampleRate
+	nanos := frame % sampleRate * uint64(time.Second) / sampleRate
+	return rw.start.Add(time.Duration(seconds)*time.Second + time.Duration(nanos))
+}
+
+func (rw *RollingWriter) closeCurrent() error {
+	w := rw.w
+	rw.w = nil
+	return w.Close()
+}
@@ -0,0 +1,150 @@
+package wavgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func rollingTestFormat() *Format {
+	return &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    10,
+		ByteRate:      20,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+}
+
+// readRollingFile returns the samples and bext chunk of a file.
+func readRollingFile(t *testing.T, path string) ([]Sample, *BroadcastExtension) {
+	r := NewReader()
+	require.NoError(t, r.Open(path))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	samples, err := r.GetSamples(int(r.GetNumSamples()))
+	require.NoError(t, err)
+	return samples, r.GetBroadcastExtension()
+}
+
+func TestRollingWriterMaxFrames(t *testing.T) {
+	dir := t.TempDir()
+	rw, err := NewRollingWriter(rollingTestFormat(), &RollingOptions{
+		Template:           filepath.Join(dir, "log-{seq}.wav"),
+		MaxFrames:          4,
+		BroadcastExtension: &BroadcastExtension{Description: "log", TimeReference: 1000},
+	})
+	require.NoError(t, err)
+
+	var want []Sample
+	for i := 0; i < 10; i += 3 {
+		batch := make([]Sample, min(3, 10-i))
+		for j := range batch {
+			batch[j][0] = i + j
+		}
+		want = append(want, batch...)
+		require.NoError(t, rw.WriteSamples(batch))
+	}
+	require.NoError(t, rw.Close())
+
+	require.Equal(t, []string{
+		filepath.Join(dir, "log-0001.wav"),
+		filepath.Join(dir, "log-0002.wav"),
+		filepath.Join(dir, "log-0003.wav"),
+	}, rw.GetPaths())
+	var got []Sample
+	for i, path := range rw.GetPaths() {
+		samples, bext := readRollingFile(t, path)
+		require.Equal(t, uint64(1000+4*i), bext.TimeReference)
+		got = append(got, samples...)
+	}
+	require.Equal(t, want, got)
+}
+
+func TestRollingWriterMaxDuration(t *testing.T) {
+	dir := t.TempDir()
+	rw, err := NewRollingWriter(rollingTestFormat(), &RollingOptions{
+		Template:    filepath.Join(dir, "{time}.wav"),
+		StartTime:   time.Date(2024, 5, 1, 23, 59, 59, 0, time.UTC),
+		MaxDuration: 1500 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.NoError(t, rw.WriteSamples(make([]Sample, 40)))
+	require.NoError(t, rw.Close())
+
+	require.Equal(t, []string{
+		filepath.Join(dir, "20240501-235959.wav"),
+		filepath.Join(dir, "20240502-000000.wav"),
+		filepath.Join(dir, "20240502-000002.wav"),
+	}, rw.GetPaths())
+	samples, _ := readRollingFile(t, rw.GetPaths()[2])
+	require.Len(t, samples, 10)
+}
+
+func TestRollingWriterMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	rw, err := NewRollingWriter(rollingTestFormat(), &RollingOptions{
+		Template: filepath.Join(dir, "{seq}.wav"),
+		MaxBytes: 44 + 8,
+		Atomic:   true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, rw.WriteSamples(make([]Sample, 8)))
+	require.Len(t, rw.GetPaths(), 2)
+	require.NoError(t, rw.Close())
+	for _, path := range rw.GetPaths() {
+		samples, _ := readRollingFile(t, path)
+		require.Len(t, samples, 4)
+	}
+}
+
+func TestRollingWriterNameCollision(t *testing.T) {
+	for _, atomic := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Atomic=%v", atomic), func(t *testing.T) {
+			dir := t.TempDir()
+			existing := filepath.Join(dir, "20240501-120000.wav")
+			require.NoError(t, os.WriteFile(existing, []byte("previous run"), 0644))
+
+			// Every file starts within the same second.
+			rw, err := NewRollingWriter(rollingTestFormat(), &RollingOptions{
+				Template:  filepath.Join(dir, "{time}.wav"),
+				StartTime: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+				MaxFrames: 2,
+				Atomic:    atomic,
+			})
+			require.NoError(t, err)
+			require.NoError(t, rw.WriteSamples([]Sample{{1}, {2}, {3}, {4}}))
+			require.NoError(t, rw.Close())
+
+			require.Equal(t, []string{
+				filepath.Join(dir, "20240501-120000-1.wav"),
+				filepath.Join(dir, "20240501-120000-2.wav"),
+			}, rw.GetPaths())
+			b, err := os.ReadFile(existing)
+			require.NoError(t, err)
+			require.Equal(t, "previous run", string(b))
+			samples, _ := readRollingFile(t, rw.GetPaths()[0])
+			require.Equal(t, []Sample{{1}, {2}}, samples)
+			samples, _ = readRollingFile(t, rw.GetPaths()[1])
+			require.Equal(t, []Sample{{3}, {4}}, samples)
+		})
+	}
+}
+
+func TestRollingWriterError(t *testing.T) {
+	_, err := NewRollingWriter(rollingTestFormat(), &RollingOptions{Template: "out.wav"})
+	require.ErrorIs(t, err, ErrInvalidTemplate)
+
+	rw, err := NewRollingWriter(rollingTestFormat(), &RollingOptions{
+		Template: filepath.Join(t.TempDir(), "{seq}.wav"),
+		MaxBytes: 44,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, rw.WriteSamples(make([]Sample, 1)), ErrLimitTooSmall)
+	require.Empty(t, rw.GetPaths())
+}
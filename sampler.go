@@ -0,0 +1,160 @@
+package wavgo
+
+import "encoding/binary"
+
+const (
+	// SMPLChunkID is the ID of the sampler chunk.
+	SMPLChunkID = "smpl"
+	// INSTChunkID is the ID of the instrument chunk.
+	INSTChunkID = "inst"
+)
+
+// Sample loop types used in SampleLoop.Type.
+const (
+	LoopForward     = 0
+	LoopAlternating = 1
+	LoopBackward    = 2
+)
+
+// Sampler holds the contents of a smpl chunk describing how a sampler
+// should play back the audio.
+type Sampler struct {
+	Manufacturer uint32 // MMA manufacturer code, zero if unspecified
+	Product      uint32 // manufacturer specific product code
+
+	// SamplePeriod is the duration of one sample in nanoseconds.
+	SamplePeriod uint32
+
+	// MIDIUnityNote is the MIDI note at which the sample plays back at its
+	// original pitch (0-127, 60 is middle C).
+	MIDIUnityNote uint32
+
+	// MIDIPitchFraction is the fraction of a semitone above MIDIUnityNote,
+	// where 0x80000000 is half a semitone.
+	MIDIPitchFraction uint32
+
+	// SMPTEFormat is the SMPTE frame rate (0, 24, 25, 29 or 30) and
+	// SMPTEOffset the offset encoded as 0xhhmmssff.
+	SMPTEFormat uint32
+	SMPTEOffset uint32
+
+	Loops []SampleLoop
+
+	// SamplerData holds manufacturer specific data following the loops.
+	SamplerData []byte
+}
+
+// SampleLoop is a loop defined in a smpl chunk. Start and End are sample
+// frame offsets; End is the last frame played in the loop.
+type SampleLoop struct {
+	CuePointID uint32
+	Type       uint32 // LoopForward, LoopAlternating or LoopBackward
+	Start      uint32
+	End        uint32
+	Fraction   uint32 // fraction of a sample at which to loop
+	PlayCount  uint32 // zero means loop forever
+}
+
+// Instrument holds the contents of an inst chunk.
+type Instrument struct {
+	UnshiftedNote uint8 // MIDI note of the recorded pitch
+	FineTune      int8  // pitch offset in cents (-50 to +50)
+	Gain          int8  // gain in dB
+	LowNote       uint8
+	HighNote      uint8
+	LowVelocity   uint8
+	HighVelocity  uint8
+}
+
+// parseSampler decodes the payload of a smpl chunk.
+func parseSampler(data []byte) (*Sampler, error) {
+	if len(data) < 36 {
+		return nil, invalidChunk(SMPLChunkID, "too short")
+	}
+	le := binary.LittleEndian
+	s := &Sampler{
+		Manufacturer:      le.Uint32(data[0:4]),
+		Product:           le.Uint32(data[4:8]),
+		SamplePeriod:      le.Uint32(data[8:12]),
+		MIDIUnityNote:     le.Uint32(data[12:16]),
+		MIDIPitchFraction: le.Uint32(data[16:20]),
+		SMPTEFormat:       le.Uint32(data[20:24]),
+		SMPTEOffset:       le.Uint32(data[24:28]),
+	}
+	numLoops := le.Uint32(data[28:32])
+	samplerDataSize := le.Uint32(data[32:36])
+	if uint64(numLoops)*24+uint64(samplerDataSize) > uint64(len(data)-36) {
+		return nil, invalidChunk(SMPLChunkID, "loops exceed chunk size")
+	}
+	s.Loops = make([]SampleLoop, numLoops)
+	for i := range s.Loops {
+		p := data[36+i*24:]
+		s.Loops[i] = SampleLoop{
+			CuePointID: le.Uint32(p[0:4]),
+			Type:       le.Uint32(p[4:8]),
+			Start:      le.Uint32(p[8:12]),
+			End:        le.Uint32(p[12:16]),
+			Fraction:   le.Uint32(p[16:20]),
+			PlayCount:  le.Uint32(p[20:24]),
+		}
+	}
+	if samplerDataSize > 0 {
+		off := 36 + int(numLoops)*24
+		s.SamplerData = append([]byte(nil), data[off:off+int(samplerDataSize)]...)
+	}
+	return s, nil
+}
+
+// encodeSampler returns the payload of a smpl chunk.
+func encodeSampler(s *Sampler) []byte {
+	le := binary.LittleEndian
+	data := make([]byte, 36+24*len(s.Loops), 36+24*len(s.Loops)+len(s.SamplerData))
+	le.PutUint32(data[0:4], s.Manufacturer)
+	le.PutUint32(data[4:8], s.Product)
+	le.PutUint32(data[8:12], s.SamplePeriod)
+	le.PutUint32(data[12:16], s.MIDIUnityNote)
+	le.PutUint32(data[16:20], s.MIDIPitchFraction)
+	le.PutUint32(data[20:24], s.SMPTEFormat)
+	le.PutUint32(data[24:28], s.SMPTEOffset)
+	le.PutUint32(data[28:32], uint32(len(s.Loops)))
+	le.PutUint32(data[32:36], uint32(len(s.SamplerData)))
+	for i, l := range s.Loops {
+		p := data[36+i*24:]
+		le.PutUint32(p[0:4], l.CuePointID)
+		le.PutUint32(p[4:8], l.Type)
+		le.PutUint32(p[8:12], l.Start)
+		le.PutUint32(p[12:16], l.End)
+		le.PutUint32(p[16:20], l.Fraction)
+		le.PutUint32(p[20:24], l.PlayCount)
+	}
+	return append(data, s.SamplerData...)
+}
+
+// parseInstrument decodes the payload of an inst chunk.
+func parseInstrument(data []byte) (*Instrument, error) {
+	if len(data) < 7 {
+		return nil, invalidChunk(INSTChunkID, "too short")
+	}
+	return &Instrument{
+		UnshiftedNote: data[0],
+		FineTune:      int8(data[1]),
+		Gain:          int8(data[2]),
+		LowNote:       data[3],
+		HighNote:      data[4],
+		LowVelocity:   data[5],
+		HighVelocity:  data[6],
+	}, nil
+}
+
+// encodeInstrument returns the 7-byte payload of an inst chunk.
+func encodeInstrument(i *Instrument) []byte {
+	return []byte{
+		i.UnshiftedNote,
+		uint8(i.FineTune),
+		uint8(i.Gain),
+		i.LowNote,
+		i.HighNote,
+		i.LowVelocity,
+		i.HighVelocity,
+	}
+}
@@ -0,0 +1,86 @@
+package wavgo
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplerRoundTrip(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    44100,
+		ByteRate:      88200,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+	sampler := &Sampler{
+		SamplePeriod:      22675,
+		MIDIUnityNote:     60,
+		MIDIPitchFraction: 0x80000000,
+		SMPTEFormat:       25,
+		SMPTEOffset:       0x01020304,
+		Loops: []SampleLoop{
+			{CuePointID: 1, Type: LoopForward, Start: 2, End: 7},
+			{CuePointID: 2, Type: LoopAlternating, Start: 0, End: 3, PlayCount: 4},
+		},
+		SamplerData: []byte{0xAA, 0xBB},
+	}
+	instrument := &Instrument{
+		UnshiftedNote: 60,
+		FineTune:      -12,
+		Gain:          -3,
+		LowNote:       48,
+		HighNote:      72,
+		LowVelocity:   1,
+		HighVelocity:  127,
+	}
+
+	filename := "testdata/TestSamplerRoundTrip.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetSampler(sampler)
+	w.SetInstrument(instrument)
+	require.NoError(t, w.WriteSamples(make([]Sample, 8)))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, sampler, r.GetSampler())
+	require.Equal(t, instrument, r.GetInstrument())
+}
+
+func TestSamplerInvalid(t *testing.T) {
+	_, err := parseSampler(make([]byte, 35))
+	require.EqualError(t, err, "invalid smpl chunk: too short")
+
+	data := encodeSampler(&Sampler{Loops: []SampleLoop{{}}})
+	_, err = parseSampler(data[:len(data)-1])
+	require.EqualError(t, err, "invalid smpl chunk: loops exceed chunk size")
+}
+
+func TestInstrumentEncodeParse(t *testing.T) {
+	inst := &Instrument{
+		UnshiftedNote: 60,
+		FineTune:      -12,
+		Gain:          -3,
+		LowNote:       48,
+		HighNote:      72,
+		LowVelocity:   1,
+		HighVelocity:  127,
+	}
+	data := encodeInstrument(inst)
+	require.Equal(t, []byte{60, 0xF4, 0xFD, 48, 72, 1, 127}, data)
+
+	got, err := parseInstrument(data)
+	require.NoError(t, err)
+	require.Equal(t, inst, got)
+
+	_, err = parseInstrument(data[:6])
+	require.EqualError(t, err, "invalid inst chunk: too short")
+}
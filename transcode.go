@@ -0,0 +1,131 @@
+package wavgo
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/takurooo/wavgo/internal/riff"
+)
+
+// DefaultTranscodeBlockSize is the number of sample frames passed to
+// TranscodeOptions.Transform at a time when BlockSize is zero.
+const DefaultTranscodeBlockSize = 4096
+
+// TranscodeOptions configures Transcode.
+type TranscodeOptions struct {
+	// Transform is called with consecutive blocks of samples read from the
+	// source and returns the samples to write. It may return a different
+	// number of samples than it receives. As a Sample holds two channels,
+	// Transform cannot be used for sources with more channels. A nil
+	// Transform copies the samples of any number of channels, scaled to the
+	// bit depth of Format.
+	Transform func(samples []Sample) ([]Sample, error)
+
+	// Format is the format of the output. A nil Format keeps the format of
+	// the source.
+	Format *Format
+
+	// SkipChunks lists the IDs of chunks that must not be copied, e.g.
+	// "PEAK" when Transform changes the levels.
+	SkipChunks []string
+
+	// BlockSize is the number of sample frames passed to Transform at a
+	// time. Zero selects DefaultTranscodeBlockSize.
+	BlockSize int
+}
+
+// Transcode writes a new WAV file to dstPath containing the remaining
+// samples of src, optionally transformed, and every other chunk of src in
+// its original order. Chunks located before the data chunk in src stay
+// before it and chunks located after it stay after it; the fmt chunk is
+// always written first. src must be loaded. On error the partially written
+// file is removed.
+func Transcode(dstPath string, src *Reader, opts *TranscodeOptions) (err error) {
+	if opts == nil {
+		opts = &TranscodeOptions{}
+	}
+	format := opts.Format
+	if format == nil {
+		srcFormat := src.GetFormat()
+		format = &srcFormat
+	}
+	blockSize := opts.BlockSize
+	if blockSize <= 0 {
+		blockSize = DefaultTranscodeBlockSize
+	}
+	numChannels := int(src.format.NumChannels)
+	if opts.Transform != nil && numChannels > len(Sample{}) {
+		return fmt.Errorf("%w: %d channels", ErrTooManyChannels, numChannels)
+	}
+	if opts.Transform == nil && format.NumChannels != src.format.NumChannels {
+		return &FormatError{Field: "NumChannels", Reason: "must match the source when copying without a Transform"}
+	}
+	skip := map[string]bool{
+		riff.FMTChunkID:  true,
+		riff.DATAChunkID: true,
+		riff.DS64ChunkID: true,
+	}
+	for _, id := range opts.SkipChunks {
+		skip[id] = true
+	}
+
+	w := NewWriter(format)
+	placement := BeforeData
+	for _, c := range src.GetChunks() {
+		if c.ID == riff.DATAChunkID {
+			placement = AfterData
+		}
+		if skip[c.ID] {
+			continue
+		}
+		data, err := c.Data()
+		if err != nil {
+			return err
+		}
+		if err := w.AddChunk(c.ID, data, placement); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Open(dstPath); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			w.Abort()
+		}
+	}()
+
+	if opts.Transform == nil {
+		buf := make([]int32, blockSize*numChannels)
+		for {
+			n, err := src.ReadInt32(buf)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err := w.WriteInt32(buf[:n*numChannels]); err != nil {
+				return err
+			}
+		}
+		return w.Close()
+	}
+	for src.GetNumSamplesLeft() > 0 {
+		n := min(blockSize, int(src.GetNumSamplesLeft()))
+		samples, err := src.GetSamples(n)
+		if err != nil {
+			return err
+		}
+		if opts.Transform != nil {
+			if samples, err = opts.Transform(samples); err != nil {
+				return err
+			}
+		}
+		if err := w.WriteSamples(samples); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
@@ -0,0 +1,126 @@
+package wavgo
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscode(t *testing.T) {
+	src := buildWAV(
+		testChunk{"JUNK", make([]byte, 4)},
+		testChunk{"fmt ", pcm16FmtData(1, 8000)},
+		testChunk{"xprv", []byte("keep")},
+		testChunk{"PEAK", make([]byte, 16)},
+		testChunk{"data", []byte{0x01, 0x00, 0x02, 0x00, 0x03, 0x00}},
+		testChunk{"xend", []byte("tail")},
+	)
+	r := &Reader{src: bytes.NewReader(src)}
+	require.NoError(t, r.Load())
+
+	filename := "testdata/TestTranscode.wav"
+	defer os.Remove(filename)
+	err := Transcode(filename, r, &TranscodeOptions{
+		Transform: func(samples []Sample) ([]Sample, error) {
+			for i := range samples {
+				samples[i][0] *= 10
+			}
+			return samples, nil
+		},
+		SkipChunks: []string{"PEAK"},
+		BlockSize:  2,
+	})
+	require.NoError(t, err)
+
+	out := NewReader()
+	require.NoError(t, out.Open(filename))
+	defer out.Close()
+	require.NoError(t, out.Load())
+
+	chunks := out.GetChunks()
+	ids := make([]string, len(chunks))
+	for i, c := range chunks {
+		ids[i] = c.ID
+	}
+	require.Equal(t, []string{"fmt ", "JUNK", "xprv", "data", "xend"}, ids)
+	data, err := chunks[4].Data()
+	require.NoError(t, err)
+	require.Equal(t, []byte("tail"), data)
+
+	samples, err := out.GetSamples(3)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{10, 0}, {20, 0}, {30, 0}}, samples)
+}
+
+func TestTranscodeTransformError(t *testing.T) {
+	r := NewReader()
+	require.NoError(t, r.Open("testdata/read_test.wav"))
+	defer r.Close()
+	require.NoError(t, r.Load())
+
+	filename := "testdata/TestTranscodeTransformError.wav"
+	errTransform := errors.New("transform failed")
+	err := Transcode(filename, r, &TranscodeOptions{
+		Transform: func(samples []Sample) ([]Sample, error) {
+			return nil, errTransform
+		},
+	})
+	require.ErrorIs(t, err, errTransform)
+	_, statErr := os.Stat(filename)
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestTranscodeCopy(t *testing.T) {
+	r := NewReader()
+	require.NoError(t, r.Open("testdata/read_test.wav"))
+	defer r.Close()
+	require.NoError(t, r.Load())
+
+	filename := "testdata/TestTranscodeCopy.wav"
+	defer os.Remove(filename)
+	require.NoError(t, Transcode(filename, r, nil))
+
+	want, err := os.ReadFile("testdata/read_test.wav")
+	require.NoError(t, err)
+	got, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestTranscodeMultichannel(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "src.wav")
+	w := NewWriter(NewPCMFormat(6, 48000, 24))
+	require.NoError(t, w.Open(srcPath))
+	require.NoError(t, w.AddChunk("xprv", []byte("keep"), BeforeData))
+	src := make([]int32, 6*10)
+	for i := range src {
+		src[i] = int32(i-30) << 8
+	}
+	require.NoError(t, w.WriteInt32(src))
+	require.NoError(t, w.Close())
+
+	r := NewReader()
+	require.NoError(t, r.Open(srcPath))
+	defer r.Close()
+	require.NoError(t, r.Load())
+
+	dstPath := filepath.Join(dir, "dst.wav")
+	err := Transcode(dstPath, r, &TranscodeOptions{
+		Transform: func(samples []Sample) ([]Sample, error) { return samples, nil },
+	})
+	require.ErrorIs(t, err, ErrTooManyChannels)
+	_, statErr := os.Stat(dstPath)
+	require.True(t, os.IsNotExist(statErr))
+
+	require.NoError(t, Transcode(dstPath, r, &TranscodeOptions{BlockSize: 3}))
+	want, err := os.ReadFile(srcPath)
+	require.NoError(t, err)
+	got, err := os.ReadFile(dstPath)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
@@ -2,7 +2,9 @@
 // with PCM format support. It offers a simple API for parsing WAV file headers,
 // extracting audio samples, and creating new WAV files.
 //
-// The library supports common bit depths (8, 16, 24, 32 bits) and provides
+// The library supports common bit depths (8, 16, 24, 32 bits), packed odd bit
+// depths such as 12-bit samples in 16-bit containers or 20-bit samples in
+// 24-bit containers, and WAVE_FORMAT_EXTENSIBLE valid-bits information. It provides
 // access to format information such as sample rate, number of channels, and
 // bits per sample through the Format struct.
 //
@@ -26,16 +28,18 @@
 //		log.Fatal(err)
 //	}
 //
-// Basic usage for writing:
+// Open detects the container from the file's magic bytes and returns a
+// loaded reader in a single call:
 //
-//	format := &wavgo.Format{
-//		AudioFormat:   wavgo.AudioFormatPCM,
-//		NumChannels:   2,
-//		SampleRate:    44100,
-//		BitsPerSample: 16,
-//		BlockAlign:    4,
-//		ByteRate:      176400,
+//	r, err := wavgo.Open("input.wav")
+//	if err != nil {
+//		log.Fatal(err)
 //	}
+//	defer r.Close()
+//
+// Basic usage for writing:
+//
+//	format := wavgo.NewPCMFormat(2, 44100, 16)
 //
 //	writer := wavgo.NewWriter(format)
 //	err := writer.Open("output.wav")
@@ -51,11 +55,18 @@
 //	}
 package wavgo
 
+import "fmt"
+
 // Audio format constants as defined by the WAV specification.
 const (
 	// AudioFormatPCM represents the standard PCM (Pulse Code Modulation) audio format.
 	// This is the most common uncompressed audio format used in WAV files.
 	AudioFormatPCM = 0x0001
+
+	// AudioFormatExtensible represents WAVE_FORMAT_EXTENSIBLE. The actual codec
+	// is given by Format.SubFormat and the number of significant bits by
+	// Format.ValidBitsPerSample.
+	AudioFormatExtensible = 0xFFFE
 )
 
 // Format describes the basic audio format information stored in a WAV file's fmt chunk.
@@ -79,12 +90,169 @@ type Format struct {
 	BlockAlign uint16
 
 	// BitsPerSample specifies the number of bits used per audio sample
-	// (typically 8, 16, 24, or 32).
+	// (typically 8, 16, 24, or 32). Odd depths such as 12 or 20 are stored
+	// in the next larger byte-aligned container.
 	BitsPerSample uint16
+
+	// ValidBitsPerSample specifies the number of significant bits in each sample
+	// when it is smaller than the container size, e.g. 20 valid bits in a 24-bit
+	// container. Zero means every bit of BitsPerSample is valid.
+	ValidBitsPerSample uint16
+
+	// ChannelMask specifies the speaker positions of a WAVE_FORMAT_EXTENSIBLE stream.
+	ChannelMask uint32
+
+	// SubFormat specifies the codec of a WAVE_FORMAT_EXTENSIBLE stream.
+	// Zero is treated as AudioFormatPCM when writing.
+	SubFormat uint16
+}
+
+// NewPCMFormat returns a PCM Format with BlockAlign and ByteRate derived
+// from the number of channels, the sample rate and the bit depth. Depths that
+// are not a multiple of 8, such as 12 or 20, are stored in the next larger
+// byte-aligned container.
+func NewPCMFormat(numChannels uint16, sampleRate uint32, bitsPerSample uint16) *Format {
+	blockAlign := numChannels * ((bitsPerSample + 7) / 8)
+	return &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   numChannels,
+		SampleRate:    sampleRate,
+		ByteRate:      sampleRate * uint32(blockAlign),
+		BlockAlign:    blockAlign,
+		BitsPerSample: bitsPerSample,
+	}
+}
+
+// Validate checks that f describes a stream the Writer can produce: the
+// number of channels, the sample rate and the bit depth must be set, the bit
+// depth must be supported, and BlockAlign and ByteRate must match the values
+// derived from the other fields. It returns a *FormatError for a missing or
+// inconsistent field and ErrUnsupportedBitsPerSample for an unsupported bit
+// depth.
+func (f *Format) Validate() error {
+	if f.isPCM() && f.BitsPerSample != 0 {
+		if _, _, err := f.sampleLayout(); err != nil {
+			return err
+		}
+	}
+	return f.validateFields()
+}
+
+// validateFields checks the fields of f like Validate, but accepts any bit
+// depth.
+func (f *Format) validateFields() error {
+	if f.NumChannels == 0 {
+		return &FormatError{Field: "NumChannels", Reason: "must be greater than 0"}
+	}
+	if f.SampleRate == 0 {
+		return &FormatError{Field: "SampleRate", Reason: "must be greater than 0"}
+	}
+	if !f.isPCM() {
+		// The block size of compressed formats is codec specific.
+		if f.BlockAlign == 0 {
+			return &FormatError{Field: "BlockAlign", Reason: "must be greater than 0"}
+		}
+		return nil
+	}
+	if f.BitsPerSample == 0 {
+		return &FormatError{Field: "BitsPerSample", Reason: "must be greater than 0"}
+	}
+	if f.BlockAlign == 0 {
+		return &FormatError{Field: "BlockAlign", Reason: "must be greater than 0"}
+	}
+	if want := f.derivedBlockAlign(); f.BlockAlign != want {
+		return &FormatError{
+			Field:  "BlockAlign",
+			Reason: fmt.Sprintf("%d does not match NumChannels * container size (%d)", f.BlockAlign, want),
+		}
+	}
+	if want := f.SampleRate * uint32(f.BlockAlign); f.ByteRate != want {
+		return &FormatError{
+			Field:  "ByteRate",
+			Reason: fmt.Sprintf("%d does not match SampleRate * BlockAlign (%d)", f.ByteRate, want),
+		}
+	}
+	return nil
+}
+
+// correct sets BlockAlign and ByteRate to the values derived from the other
+// fields and returns a description of each change.
+func (f *Format) correct() []string {
+	if !f.isPCM() || f.NumChannels == 0 || f.BitsPerSample == 0 {
+		return nil
+	}
+	var fixes []string
+	if blockAlign := f.derivedBlockAlign(); f.BlockAlign != blockAlign {
+		fixes = append(fixes, fmt.Sprintf("BlockAlign: %d -> %d", f.BlockAlign, blockAlign))
+		f.BlockAlign = blockAlign
+	}
+	if byteRate := f.SampleRate * uint32(f.BlockAlign); f.ByteRate != byteRate {
+		fixes = append(fixes, fmt.Sprintf("ByteRate: %d -> %d", f.ByteRate, byteRate))
+		f.ByteRate = byteRate
+	}
+	return fixes
+}
+
+// isPCM reports whether f stores uncompressed samples, whose BlockAlign and
+// ByteRate follow from the other fields.
+func (f *Format) isPCM() bool {
+	return f.AudioFormat == AudioFormatPCM || f.isExtensible()
+}
+
+// derivedBlockAlign returns BlockAlign if it holds a whole container of at
+// least BitsPerSample bits for every channel, such as 24-bit samples in
+// 32-bit containers, and the size of a frame of byte-aligned containers
+// otherwise.
+func (f *Format) derivedBlockAlign() uint16 {
+	bytesPerSample := (f.BitsPerSample + 7) / 8
+	if f.BlockAlign%f.NumChannels == 0 && f.BlockAlign/f.NumChannels >= bytesPerSample {
+		return f.BlockAlign
+	}
+	return f.NumChannels * bytesPerSample
+}
+
+// isExtensible reports whether the format must be stored as WAVE_FORMAT_EXTENSIBLE.
+func (f *Format) isExtensible() bool {
+	if f.AudioFormat == AudioFormatExtensible {
+		return true
+	}
+	return f.ValidBitsPerSample != 0 && f.ValidBitsPerSample != f.BitsPerSample
+}
+
+// sampleLayout returns the container size and the number of valid bits of
+// each sample. Samples are stored left-justified in their container, so a
+// value must be shifted by containerBits-validBits to get its magnitude.
+func (f *Format) sampleLayout() (containerBits, validBits int, err error) {
+	containerBits = (int(f.BitsPerSample) + 7) / 8 * 8
+	if f.NumChannels != 0 && f.BlockAlign != 0 && f.BlockAlign%f.NumChannels == 0 {
+		if bits := int(f.BlockAlign/f.NumChannels) * 8; bits > containerBits {
+			containerBits = bits
+		}
+	}
+	validBits = int(f.BitsPerSample)
+	if f.ValidBitsPerSample != 0 {
+		validBits = int(f.ValidBitsPerSample)
+	}
+
+	switch containerBits {
+	case 8:
+		if validBits != 8 {
+			return 0, 0, ErrUnsupportedBitsPerSample
+		}
+	case 16, 24, 32:
+		if validBits <= 8 || validBits > containerBits {
+			return 0, 0, ErrUnsupportedBitsPerSample
+		}
+	default:
+		return 0, 0, ErrUnsupportedBitsPerSample
+	}
+	return containerBits, validBits, nil
 }
 
 // Sample represents a single audio sample frame that can hold data for up to two channels.
 // For mono audio, only index 0 is used. For stereo audio, index 0 represents the left
 // channel and index 1 represents the right channel. The values are stored as signed
 // integers and their interpretation depends on the bit depth specified in the Format.
+// Files with more channels are read and written with the interleaved methods,
+// such as Reader.ReadInt32 and Writer.WriteInt32.
 type Sample [2]int
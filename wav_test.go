@@ -0,0 +1,100 @@
+package wavgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testChunk is a RIFF sub-chunk used to assemble WAV fixtures in tests.
+type testChunk struct {
+	id   string
+	data []byte
+}
+
+// pcm16FmtData returns the payload of a 16-bit PCM fmt chunk.
+func pcm16FmtData(numChannels uint16, sampleRate uint32) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, uint16(AudioFormatPCM))
+	binary.Write(buf, binary.LittleEndian, numChannels)
+	binary.Write(buf, binary.LittleEndian, sampleRate)
+	binary.Write(buf, binary.LittleEndian, sampleRate*uint32(numChannels)*2)
+	binary.Write(buf, binary.LittleEndian, numChannels*2)
+	binary.Write(buf, binary.LittleEndian, uint16(16))
+	return buf.Bytes()
+}
+
+// buildRIFF assembles a RIFF file with the given chunk ID, form type and sub-chunks.
+func buildRIFF(id, form string, chunks ...testChunk) []byte {
+	body := &bytes.Buffer{}
+	body.WriteString(form)
+	for _, c := range chunks {
+		body.WriteString(c.id)
+		binary.Write(body, binary.LittleEndian, uint32(len(c.data)))
+		body.Write(c.data)
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString(id)
+	binary.Write(buf, binary.LittleEndian, uint32(body.Len()))
+	buf.Write(body.Bytes())
+	return buf.Bytes()
+}
+
+// buildWAV assembles a RIFF/WAVE file from the given sub-chunks.
+func buildWAV(chunks ...testChunk) []byte {
+	return buildRIFF("RIFF", "WAVE", chunks...)
+}
+
+func TestNewPCMFormat(t *testing.T) {
+	require.Equal(t, &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   2,
+		SampleRate:    48000,
+		ByteRate:      192000,
+		BlockAlign:    4,
+		BitsPerSample: 16,
+	}, NewPCMFormat(2, 48000, 16))
+
+	format := NewPCMFormat(1, 44100, 20)
+	require.Equal(t, uint16(3), format.BlockAlign)
+	require.Equal(t, uint32(132300), format.ByteRate)
+	require.NoError(t, format.Validate())
+}
+
+func TestFormatValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *Format)
+		err    string
+	}{
+		{"Valid", func(f *Format) {}, ""},
+		{"24In32", func(f *Format) { f.BitsPerSample = 24; f.BlockAlign = 8; f.ByteRate = 384000 }, ""},
+		{"ZeroChannels", func(f *Format) { f.NumChannels = 0 }, "invalid NumChannels: must be greater than 0"},
+		{"ZeroSampleRate", func(f *Format) { f.SampleRate = 0 }, "invalid SampleRate: must be greater than 0"},
+		{"ZeroBitsPerSample", func(f *Format) { f.BitsPerSample = 0 }, "invalid BitsPerSample: must be greater than 0"},
+		{"BlockAlign", func(f *Format) { f.BlockAlign = 3 }, "invalid BlockAlign: 3 does not match NumChannels * container size (4)"},
+		{"ByteRate", func(f *Format) { f.ByteRate = 128000 }, "invalid ByteRate: 128000 does not match SampleRate * BlockAlign (192000)"},
+		{"Compressed", func(f *Format) { f.AudioFormat = 0x0011; f.BlockAlign = 1024; f.ByteRate = 1 }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := NewPCMFormat(2, 48000, 16)
+			tt.modify(format)
+			err := format.Validate()
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+			var formatErr *FormatError
+			require.True(t, errors.As(err, &formatErr))
+			require.ErrorIs(t, err, ErrInvalidFormat)
+		})
+	}
+
+	format := NewPCMFormat(2, 48000, 7)
+	require.ErrorIs(t, format.Validate(), ErrUnsupportedBitsPerSample)
+}
@@ -2,7 +2,13 @@ package wavgo
 
 import (
 	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/takurooo/wavgo/internal/binio"
 	"github.com/takurooo/wavgo/internal/riff"
@@ -11,17 +17,60 @@ import (
 // Writer provides functionality to create and write WAV audio files.
 // It uses the provided Format configuration to structure the output file
 // and supports writing audio samples with automatic header generation.
+// Output is buffered; it reaches the file at the latest when the Writer is
+// closed or a checkpoint is taken.
 type Writer struct {
 	f                   *os.File
 	bw                  *binio.Writer
+	buf                 []byte
+	values              []int
 	format              *Format
 	headerWritten       bool
 	numWrittenSamples   uint32
-	headerSize          uint32
 	riffChunkSizeOffset int64
 	dataChunkSizeOffset int64
+	info                *Info
+	bext                *BroadcastExtension
+	cuePoints           []CuePoint
+	sampler             *Sampler
+	instrument          *Instrument
+	acid                *ACID
+	ixml                []byte
+	id3                 *ID3Tag
+	cart                *Cart
+	axml                []byte
+	chna                *ChannelAssignment
+	peakChunk           bool
+	envelopeBlockSize   uint32
+	peaks               *peakTracker
+	peakChunkOffset     int64
+	customChunks        []customChunk
+	checkpoints         CheckpointOptions
+	checkpointSamples   uint32
+	checkpointTime      time.Time
+	path                string
+	tmpPath             string
 }
 
+// CheckpointOptions configures the periodic header updates enabled with
+// Writer.EnableCheckpoints.
+type CheckpointOptions struct {
+	// Frames triggers a checkpoint once this many sample frames have been
+	// written since the last one. Zero disables frame-based checkpoints.
+	Frames uint32
+
+	// Interval triggers a checkpoint once this much time has passed since
+	// the last one. Zero disables time-based checkpoints.
+	Interval time.Duration
+
+	// Sync makes every checkpoint fsync the file, so that the checkpointed
+	// state also survives a power loss.
+	Sync bool
+}
+
+// writeBufferSize is the size of the output buffer of a Writer.
+const writeBufferSize = 64 << 10
+
 // NewWriter creates a new WAV file writer configured with the specified Format.
 // The format parameter defines the audio characteristics such as sample rate,
 // bit depth, and channel configuration. The writer must be opened with Open()
@@ -38,18 +87,118 @@ func (w *Writer) Open(filePath string) error {
 	if err != nil {
 		return err
 	}
+	w.openFile(f, filePath)
+	return nil
+}
+
+// openFile makes f, created for filePath, the destination of the Writer.
+func (w *Writer) openFile(f *os.File, filePath string) {
 	w.f = f
-	w.bw = binio.NewWriter(f)
+	w.bw = binio.NewBufferedWriter(f, writeBufferSize)
+	w.path = filePath
+}
+
+// OpenAtomic is like Open, but the output is written to a temporary file in
+// the same directory and only renamed to filePath by a successful Close, so
+// that other processes never see a partially written file at filePath. The
+// temporary file is removed if Close fails or Abort is called.
+func (w *Writer) OpenAtomic(filePath string) error {
+	f, err := createTemp(filePath)
+	if err != nil {
+		return err
+	}
+	w.openFile(f, filePath)
+	w.tmpPath = f.Name()
 	return nil
 }
 
+// createTemp creates a new hidden file next to filePath. Unlike
+// os.CreateTemp it applies the same permissions as os.Create, so the file
+// keeps the expected mode once it is renamed to filePath.
+func createTemp(filePath string) (*os.File, error) {
+	dir, base := filepath.Split(filePath)
+	for range 10000 {
+		name := filepath.Join(dir, "."+base+"."+strconv.FormatUint(rand.Uint64(), 36)+".tmp")
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if !os.IsExist(err) {
+			return f, err
+		}
+	}
+	return nil, fmt.Errorf("cannot create a temporary file for %s: %w", filePath, os.ErrExist)
+}
+
+// Abort discards the output: it closes and removes the file being written.
+// In atomic mode, a file already present at the destination path is left
+// untouched. The Writer cannot be used after Abort.
+func (w *Writer) Abort() error {
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	path := w.path
+	if w.tmpPath != "" {
+		path = w.tmpPath
+	}
+	if rmErr := os.Remove(path); err == nil {
+		err = rmErr
+	}
+	w.f = nil
+	return err
+}
+
 // Close finalizes the WAV file by updating the RIFF and data chunk sizes
 // in the header, syncing the file to disk, and closing the file handle.
 // This method must be called to ensure the WAV file is properly formatted
-// and all data is written to disk.
+// and all data is written to disk. If peak chunks are enabled, the PEAK
+// chunk is filled in and the levl chunk is appended after the data chunk.
+// In atomic mode the file is then renamed to the path given to OpenAtomic,
+// or removed if an error occurred. Once the file is closed, further calls to
+// Close and Abort do nothing, so Abort can be deferred to clean up after an
+// error.
 func (w *Writer) Close() error {
+	if w.f == nil {
+		return nil
+	}
+	if w.tmpPath == "" {
+		if err := w.close(); err != nil {
+			return err
+		}
+		w.f = nil
+		return nil
+	}
+	err := w.close()
+	if err != nil {
+		w.f.Close()
+	}
+	w.f = nil
+	if err != nil {
+		os.Remove(w.tmpPath)
+		return err
+	}
+	if err := os.Rename(w.tmpPath, w.path); err != nil {
+		os.Remove(w.tmpPath)
+		return err
+	}
+	return nil
+}
+
+func (w *Writer) close() error {
+	if !w.headerWritten {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
 	dataChunkSize := w.numWrittenSamples * uint32(w.format.BlockAlign)
-	riffChunkSize := dataChunkSize + w.headerSize - 8
+	if dataChunkSize%2 == 1 {
+		w.bw.WriteU8(0)
+	}
+	w.writeCustomChunks(AfterData)
+	now := time.Now()
+	if w.peaks != nil && w.envelopeBlockSize != 0 {
+		w.writeChunk(LEVLChunkID, encodePeakEnvelope(w.peaks.envelope(now)))
+	}
+	riffChunkSize := uint32(w.bw.GetOffset()) - 8
 	w.bw.SetOffset(w.riffChunkSizeOffset)
 	w.bw.WriteU32(riffChunkSize, binary.LittleEndian)
 	if w.bw.Err() != nil {
@@ -60,6 +209,13 @@ func (w *Writer) Close() error {
 	if w.bw.Err() != nil {
 		return w.bw.Err()
 	}
+	if w.peaks != nil && w.peakChunk {
+		w.bw.SetOffset(w.peakChunkOffset)
+		w.writeChunk(PEAKChunkID, encodePeak(w.peaks.peak(now)))
+	}
+	if err := w.bw.Flush(); err != nil {
+		return err
+	}
 	if err := w.f.Sync(); err != nil {
 		return err
 	}
@@ -69,21 +225,172 @@ func (w *Writer) Close() error {
 	return nil
 }
 
+// SetADM embeds doc verbatim as the axml chunk holding ADM XML metadata.
+// The Writer produces RIFF/WAVE files, which ITU-R BS.2088 accepts for BW64
+// content smaller than 4 GiB. It must be called before the first call to
+// WriteSamples.
+func (w *Writer) SetADM(doc []byte) {
+	w.axml = doc
+}
+
+// SetChannelAssignment sets the chna chunk mapping tracks to ADM track
+// UIDs. It must be called before the first call to WriteSamples.
+func (w *Writer) SetChannelAssignment(chna *ChannelAssignment) {
+	w.chna = chna
+}
+
+// AddChunk adds a chunk with the given ID and data to the output, placed
+// before or after the data chunk. Chunks are written in the order they are
+// added, after the chunks set with the typed setters such as SetInfo. The
+// fmt and data chunks cannot be added. Chunks placed BeforeData must be
+// added before the first call to WriteSamples; later ones return
+// ErrHeaderWritten.
+func (w *Writer) AddChunk(id string, data []byte, placement ChunkPlacement) error {
+	if err := validateChunkID(id); err != nil {
+		return err
+	}
+	if placement == BeforeData && w.headerWritten {
+		return fmt.Errorf("%w: cannot add %q before the data chunk", ErrHeaderWritten, id)
+	}
+	w.customChunks = append(w.customChunks, customChunk{id, data, placement})
+	return nil
+}
+
+// EnablePeakChunk makes the Writer compute the peak of each channel from the
+// samples passed to WriteSamples and store it in a PEAK chunk at Close. It
+// must be called before the first call to WriteSamples.
+func (w *Writer) EnablePeakChunk() {
+	w.peakChunk = true
+}
+
+// EnablePeakEnvelope makes the Writer compute an EBU levl peak envelope with
+// one point per blockSize sample frames and append it after the data chunk
+// at Close. A blockSize of zero selects DefaultPeakEnvelopeBlockSize. It
+// must be called before the first call to WriteSamples.
+func (w *Writer) EnablePeakEnvelope(blockSize uint32) {
+	if blockSize == 0 {
+		blockSize = DefaultPeakEnvelopeBlockSize
+	}
+	w.envelopeBlockSize = blockSize
+}
+
+// EnableCheckpoints makes the Writer update the RIFF and data chunk sizes in
+// the header periodically while samples are written, instead of only at
+// Close. The file on disk is then a valid WAV file holding every sample frame
+// written up to the last checkpoint, so a crash or kill loses at most the
+// last interval. Checkpoints are taken at the end of a WriteSamples call, so
+// the interval is rounded up to the size of the written batches. Chunks
+// placed after the data chunk, such as levl, are only written at Close. It
+// must be called before the first call to WriteSamples.
+func (w *Writer) EnableCheckpoints(opts CheckpointOptions) {
+	w.checkpoints = opts
+}
+
+// SetInfo sets the metadata written as a LIST/INFO chunk. It must be called
+// before the first call to WriteSamples.
+func (w *Writer) SetInfo(info *Info) {
+	w.info = info
+}
+
+// SetBroadcastExtension sets the bext chunk that turns the output into a
+// Broadcast Wave file. It must be called before the first call to WriteSamples.
+func (w *Writer) SetBroadcastExtension(bext *BroadcastExtension) {
+	w.bext = bext
+}
+
+// SetCuePoints sets the markers and regions written as a cue chunk and an
+// adtl LIST chunk. It must be called before the first call to WriteSamples.
+func (w *Writer) SetCuePoints(cuePoints []CuePoint) {
+	w.cuePoints = cuePoints
+}
+
+// SetSampler sets the smpl chunk describing loop points and MIDI tuning.
+// It must be called before the first call to WriteSamples.
+func (w *Writer) SetSampler(sampler *Sampler) {
+	w.sampler = sampler
+}
+
+// SetInstrument sets the inst chunk describing the key and velocity range.
+// It must be called before the first call to WriteSamples.
+func (w *Writer) SetInstrument(instrument *Instrument) {
+	w.instrument = instrument
+}
+
+// SetACID sets the acid chunk used by DAWs to tempo-sync loops. It must be
+// called before the first call to WriteSamples.
+func (w *Writer) SetACID(acid *ACID) {
+	w.acid = acid
+}
+
+// SetIXML sets the iXML chunk from a typed document. To update the iXML of
+// an existing file, pass the document returned by Reader.GetIXML after
+// modifying it; elements without a dedicated field are preserved. It must be
+// called before the first call to WriteSamples.
+func (w *Writer) SetIXML(ixml *IXML) error {
+	doc, err := ixml.Marshal()
+	if err != nil {
+		return err
+	}
+	w.ixml = doc
+	return nil
+}
+
+// SetIXMLRaw embeds doc verbatim as the iXML chunk. It must be called before
+// the first call to WriteSamples.
+func (w *Writer) SetIXMLRaw(doc []byte) {
+	w.ixml = doc
+}
+
+// SetID3 sets the ID3 tag written as an "id3 " chunk. It must be called
+// before the first call to WriteSamples.
+func (w *Writer) SetID3(tag *ID3Tag) {
+	w.id3 = tag
+}
+
+// SetCart sets the AES46 cart chunk used by radio automation systems. It
+// must be called before the first call to WriteSamples.
+func (w *Writer) SetCart(cart *Cart) {
+	w.cart = cart
+}
+
 func (w *Writer) writeHeader() error {
+	if err := w.format.Validate(); err != nil {
+		return err
+	}
 	// riff chunk
 	w.bw.WriteS32(riff.RIFFChunkID, binary.BigEndian)
 	w.riffChunkSizeOffset = w.bw.GetOffset()
 	w.bw.WriteU32(0, binary.LittleEndian) // dummy write
 	w.bw.WriteS32("WAVE", binary.BigEndian)
 	// fmt chunk
-	w.bw.WriteS32(riff.FMTChunkID, binary.BigEndian)
-	w.bw.WriteU32(0x10, binary.LittleEndian)
-	w.bw.WriteU16(w.format.AudioFormat, binary.LittleEndian)
-	w.bw.WriteU16(w.format.NumChannels, binary.LittleEndian)
-	w.bw.WriteU32(w.format.SampleRate, binary.LittleEndian)
-	w.bw.WriteU32(w.format.ByteRate, binary.LittleEndian)
-	w.bw.WriteU16(w.format.BlockAlign, binary.LittleEndian)
-	w.bw.WriteU16(w.format.BitsPerSample, binary.LittleEndian)
+	if w.format.isExtensible() {
+		w.writeExtensibleFormat()
+	} else {
+		w.bw.WriteS32(riff.FMTChunkID, binary.BigEndian)
+		w.bw.WriteU32(0x10, binary.LittleEndian)
+		w.bw.WriteU16(w.format.AudioFormat, binary.LittleEndian)
+		w.bw.WriteU16(w.format.NumChannels, binary.LittleEndian)
+		w.bw.WriteU32(w.format.SampleRate, binary.LittleEndian)
+		w.bw.WriteU32(w.format.ByteRate, binary.LittleEndian)
+		w.bw.WriteU16(w.format.BlockAlign, binary.LittleEndian)
+		w.bw.WriteU16(w.format.BitsPerSample, binary.LittleEndian)
+	}
+	// metadata chunks
+	if err := w.writeMetadata(); err != nil {
+		return err
+	}
+	// PEAK chunk, filled in at Close
+	if w.peakChunk || w.envelopeBlockSize != 0 {
+		peaks, err := newPeakTracker(w.format, w.envelopeBlockSize)
+		if err != nil {
+			return err
+		}
+		w.peaks = peaks
+	}
+	if w.peakChunk {
+		w.peakChunkOffset = w.bw.GetOffset()
+		w.writeChunk(PEAKChunkID, encodePeak(&Peak{Channels: make([]ChannelPeak, w.format.NumChannels)}))
+	}
 	// data chunk
 	w.bw.WriteS32(riff.DATAChunkID, binary.BigEndian)
 	w.dataChunkSizeOffset = w.bw.GetOffset()
@@ -91,49 +398,263 @@ func (w *Writer) writeHeader() error {
 	if w.bw.Err() != nil {
 		return w.bw.Err()
 	}
-
-	w.headerSize = uint32(w.bw.GetOffset())
 	return nil
 }
 
+// writeMetadata writes the optional metadata chunks placed before the data chunk.
+func (w *Writer) writeMetadata() error {
+	if w.bext != nil {
+		w.writeChunk(BEXTChunkID, encodeBroadcastExtension(w.bext))
+	}
+	if len(w.cuePoints) > 0 {
+		cueData, adtlData := encodeCuePoints(w.cuePoints)
+		w.writeChunk(CUEChunkID, cueData)
+		if adtlData != nil {
+			w.writeChunk(riff.LISTChunkID, adtlData)
+		}
+	}
+	if w.info != nil {
+		data, err := encodeInfo(w.info)
+		if err != nil {
+			return err
+		}
+		w.writeChunk(riff.LISTChunkID, data)
+	}
+	if w.sampler != nil {
+		w.writeChunk(SMPLChunkID, encodeSampler(w.sampler))
+	}
+	if w.instrument != nil {
+		w.writeChunk(INSTChunkID, encodeInstrument(w.instrument))
+	}
+	if w.acid != nil {
+		w.writeChunk(ACIDChunkID, encodeACID(w.acid))
+	}
+	if w.ixml != nil {
+		w.writeChunk(IXMLChunkID, w.ixml)
+	}
+	if w.id3 != nil {
+		w.writeChunk(ID3ChunkID, encodeID3(w.id3))
+	}
+	if w.cart != nil {
+		w.writeChunk(CARTChunkID, encodeCart(w.cart))
+	}
+	if w.chna != nil {
+		w.writeChunk(CHNAChunkID, encodeChannelAssignment(w.chna))
+	}
+	if w.axml != nil {
+		w.writeChunk(AXMLChunkID, w.axml)
+	}
+	w.writeCustomChunks(BeforeData)
+	return w.bw.Err()
+}
+
+// writeCustomChunks writes the chunks added with AddChunk for the given placement.
+func (w *Writer) writeCustomChunks(placement ChunkPlacement) {
+	for _, c := range w.customChunks {
+		if c.placement == placement {
+			w.writeChunk(c.id, c.data)
+		}
+	}
+}
+
+// writeChunk writes a chunk header and data followed by a pad byte if the
+// data has an odd length.
+func (w *Writer) writeChunk(id string, data []byte) {
+	writeChunkTo(w.bw, id, data)
+}
+
+// ksDataFormatSubtypeTail is the common tail of the KSDATAFORMAT_SUBTYPE_*
+// GUIDs; the first two bytes hold the codec.
+var ksDataFormatSubtypeTail = []byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+
+func (w *Writer) writeExtensibleFormat() {
+	containerBits, validBits, err := w.format.sampleLayout()
+	if err != nil {
+		containerBits, validBits = int(w.format.BitsPerSample), int(w.format.ValidBitsPerSample)
+	}
+	subFormat := w.format.SubFormat
+	if subFormat == 0 {
+		subFormat = AudioFormatPCM
+	}
+	w.bw.WriteS32(riff.FMTChunkID, binary.BigEndian)
+	w.bw.WriteU32(40, binary.LittleEndian)
+	w.bw.WriteU16(AudioFormatExtensible, binary.LittleEndian)
+	w.bw.WriteU16(w.format.NumChannels, binary.LittleEndian)
+	w.bw.WriteU32(w.format.SampleRate, binary.LittleEndian)
+	w.bw.WriteU32(w.format.ByteRate, binary.LittleEndian)
+	w.bw.WriteU16(w.format.BlockAlign, binary.LittleEndian)
+	w.bw.WriteU16(uint16(containerBits), binary.LittleEndian)
+	w.bw.WriteU16(22, binary.LittleEndian) // cbSize
+	w.bw.WriteU16(uint16(validBits), binary.LittleEndian)
+	w.bw.WriteU32(w.format.ChannelMask, binary.LittleEndian)
+	w.bw.WriteU16(subFormat, binary.LittleEndian)
+	w.bw.WriteRaw(ksDataFormatSubtypeTail)
+}
+
 // WriteSamples writes the provided audio samples to the WAV file. On the first
 // call, this method automatically writes the WAV header before writing sample data.
 // Each Sample in the slice should contain data for all channels defined in the Format.
 // The method handles the conversion of sample data to the appropriate bit depth
-// and byte order as specified in the format configuration.
+// and byte order as specified in the format configuration. Samples with fewer
+// valid bits than their container are expected right-justified and are
+// left-justified in the container when written. Formats with more than two
+// channels return ErrTooManyChannels; write them with WriteInt32 or the other
+// interleaved methods.
 func (w *Writer) WriteSamples(samples []Sample) error {
-	if !w.headerWritten {
-		err := w.writeHeader()
-		if err != nil {
+	if n := int(w.format.NumChannels); n > len(Sample{}) {
+		return fmt.Errorf("%w: %d channels", ErrTooManyChannels, n)
+	}
+	containerBits, validBits, err := w.beginWrite(len(samples))
+	if err != nil {
+		return err
+	}
+	for len(samples) > 0 {
+		n := min(len(samples), sampleBlockFrames)
+		if err := w.writeBlock(samples[:n], containerBits, validBits); err != nil {
 			return err
 		}
-		w.headerWritten = true
+		samples = samples[n:]
 	}
+	return w.endWrite()
+}
+
+// WriteInt16 writes interleaved 16-bit samples, whose length must be a
+// multiple of NumChannels. Samples are scaled to the bit depth of the format,
+// dropping extra precision, and stored unsigned for 8-bit formats. Like
+// WriteSamples it writes the header on the first call, and it does not
+// allocate once the Writer's buffers have grown.
+func (w *Writer) WriteInt16(src []int16) error {
+	return writeInterleaved(w, src, sampleScale.fromInt16)
+}
 
-	var (
-		numChannels   = int(w.format.NumChannels)
-		bitsPerSample = int(w.format.BitsPerSample)
-	)
-	for _, sample := range samples {
-		for ch := 0; ch < numChannels; ch++ {
-			switch bitsPerSample {
-			case 8:
-				w.bw.WriteU8(uint8(sample[ch]))
-			case 16:
-				w.bw.WriteU16(uint16(sample[ch]), binary.LittleEndian)
-			case 24:
-				w.bw.WriteU24(uint32(sample[ch]), binary.LittleEndian)
-			case 32:
-				w.bw.WriteU32(uint32(sample[ch]), binary.LittleEndian)
-			default:
-				return ErrUnsupportedBitsPerSample
+// WriteInt32 is like WriteInt16, but takes samples in the 32-bit range.
+func (w *Writer) WriteInt32(src []int32) error {
+	return writeInterleaved(w, src, sampleScale.fromInt32)
+}
+
+// WriteFloat32 is like WriteInt16, but takes floating point samples where -1
+// and 1 are full scale. Samples are rounded to the nearest value and clipped
+// to the range of the format.
+func (w *Writer) WriteFloat32(src []float32) error {
+	return writeInterleaved(w, src, sampleScale.fromFloat32)
+}
+
+// writeInterleaved writes the sample frames of src, converting each sample
+// with convert.
+func writeInterleaved[T int16 | int32 | float32](w *Writer, src []T, convert func(sampleScale, T) int) error {
+	numChannels := int(w.format.NumChannels)
+	if numChannels == 0 || len(src)%numChannels != 0 {
+		return fmt.Errorf("%w: %d samples for %d channels", ErrPartialFrame, len(src), numChannels)
+	}
+	containerBits, validBits, err := w.beginWrite(len(src) / numChannels)
+	if err != nil {
+		return err
+	}
+	scale := newSampleScale(validBits)
+	for len(src) > 0 {
+		n := min(len(src)/numChannels, sampleBlockFrames) * numChannels
+		w.values = w.values[:0]
+		for _, v := range src[:n] {
+			w.values = append(w.values, convert(scale, v))
+		}
+		w.buf = encodeInterleaved(w.buf[:0], w.values, containerBits, containerBits-validBits)
+		if w.peaks != nil {
+			for i := 0; i < n; i += numChannels {
+				w.peaks.add(w.values[i : i+numChannels])
 			}
+		}
+		if err := w.writeData(n / numChannels); err != nil {
+			return err
+		}
+		src = src[n:]
+	}
+	return w.endWrite()
+}
 
-			if w.bw.Err() != nil {
-				return w.bw.Err()
+// beginWrite writes the header if needed and checks that numFrames more
+// sample frames fit in the file. It returns the sample layout of the format.
+func (w *Writer) beginWrite(numFrames int) (containerBits, validBits int, err error) {
+	if !w.headerWritten {
+		if err := w.writeHeader(); err != nil {
+			return 0, 0, err
+		}
+		w.headerWritten = true
+		if w.checkpointsEnabled() {
+			if err := w.checkpoint(); err != nil {
+				return 0, 0, err
 			}
 		}
-		w.numWrittenSamples++
 	}
+	containerBits, validBits, err = w.format.sampleLayout()
+	if err != nil {
+		return 0, 0, err
+	}
+	if w.bw.GetOffset()+int64(numFrames)*int64(w.format.BlockAlign)-8 > math.MaxUint32 {
+		return 0, 0, ErrFileTooLarge
+	}
+	return containerBits, validBits, nil
+}
+
+// writeBlock encodes samples into the reusable buffer and writes them.
+func (w *Writer) writeBlock(samples []Sample, containerBits, validBits int) error {
+	numChannels := int(w.format.NumChannels)
+	w.buf = encodeSamples(w.buf[:0], samples, numChannels, containerBits, containerBits-validBits)
+	if w.peaks != nil {
+		for i := range samples {
+			w.peaks.add(samples[i][:numChannels])
+		}
+	}
+	return w.writeData(len(samples))
+}
+
+// writeData writes the numFrames sample frames encoded in the reusable buffer.
+func (w *Writer) writeData(numFrames int) error {
+	w.bw.WriteRaw(w.buf)
+	if w.bw.Err() != nil {
+		return w.bw.Err()
+	}
+	w.numWrittenSamples += uint32(numFrames)
+	return nil
+}
+
+// endWrite takes a checkpoint if one is due.
+func (w *Writer) endWrite() error {
+	if w.checkpointDue() {
+		return w.checkpoint()
+	}
+	return nil
+}
+
+func (w *Writer) checkpointsEnabled() bool {
+	return w.checkpoints.Frames != 0 || w.checkpoints.Interval != 0
+}
+
+// checkpointDue reports whether a checkpoint interval has elapsed.
+func (w *Writer) checkpointDue() bool {
+	if w.checkpoints.Frames != 0 && w.numWrittenSamples-w.checkpointSamples >= w.checkpoints.Frames {
+		return true
+	}
+	return w.checkpoints.Interval != 0 && time.Since(w.checkpointTime) >= w.checkpoints.Interval
+}
+
+// checkpoint writes the current RIFF and data chunk sizes to the header, so
+// that the file on disk is complete up to the last written sample frame.
+func (w *Writer) checkpoint() error {
+	end := w.bw.GetOffset()
+	w.bw.SetOffset(w.riffChunkSizeOffset)
+	w.bw.WriteU32(uint32(end-8), binary.LittleEndian)
+	w.bw.SetOffset(w.dataChunkSizeOffset)
+	w.bw.WriteU32(w.numWrittenSamples*uint32(w.format.BlockAlign), binary.LittleEndian)
+	w.bw.SetOffset(end)
+	if w.bw.Err() != nil {
+		return w.bw.Err()
+	}
+	if w.checkpoints.Sync {
+		if err := w.f.Sync(); err != nil {
+			return err
+		}
+	}
+	w.checkpointSamples = w.numWrittenSamples
+	w.checkpointTime = time.Now()
 	return nil
 }
@@ -1,9 +1,14 @@
 package wavgo
 
 import (
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -14,7 +19,7 @@ func TestWriter(t *testing.T) {
 		AudioFormat:   AudioFormatPCM,
 		NumChannels:   2,
 		SampleRate:    48000,
-		ByteRate:      128000,
+		ByteRate:      192000,
 		BlockAlign:    4,
 		BitsPerSample: 16,
 	}
@@ -288,3 +293,444 @@ func TestWriterLargeFile(t *testing.T) {
 	expectedSize := int64(44 + 1000*2) // Header + samples * bytes per sample
 	require.Equal(t, expectedSize, info.Size())
 }
+
+func TestWriterValidBits(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		samples []Sample
+	}{
+		{
+			name: "12In16",
+			format: Format{
+				AudioFormat: AudioFormatPCM, NumChannels: 1, SampleRate: 8000,
+				ByteRate: 16000, BlockAlign: 2, BitsPerSample: 12,
+			},
+			samples: []Sample{{2047, 0}, {-2048, 0}},
+		},
+		{
+			name: "20In24",
+			format: Format{
+				AudioFormat: AudioFormatPCM, NumChannels: 2, SampleRate: 48000,
+				ByteRate: 288000, BlockAlign: 6, BitsPerSample: 24, ValidBitsPerSample: 20,
+			},
+			samples: []Sample{{0x7FFFF, -0x80000}, {-1, 1}},
+		},
+		{
+			name: "24In32",
+			format: Format{
+				AudioFormat: AudioFormatExtensible, NumChannels: 2, SampleRate: 96000,
+				ByteRate: 768000, BlockAlign: 8, BitsPerSample: 32, ValidBitsPerSample: 24,
+				ChannelMask: 0x3, SubFormat: AudioFormatPCM,
+			},
+			samples: []Sample{{-8388608, 8388607}, {-100000, 100000}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := "testdata/TestWriterValidBits_" + tt.name + ".wav"
+			format := tt.format
+			w := NewWriter(&format)
+			require.NoError(t, w.Open(filename))
+			defer os.Remove(filename)
+			require.NoError(t, w.WriteSamples(tt.samples))
+			require.NoError(t, w.Close())
+
+			r := NewReader()
+			require.NoError(t, r.Open(filename))
+			defer r.Close()
+			require.NoError(t, r.Load())
+			got, err := r.GetSamples(len(tt.samples))
+			require.NoError(t, err)
+			require.Equal(t, tt.samples, got)
+			if format.isExtensible() {
+				require.Equal(t, uint16(AudioFormatExtensible), r.GetFormat().AudioFormat)
+				require.Equal(t, format.ValidBitsPerSample, r.GetFormat().ValidBitsPerSample)
+			}
+		})
+	}
+}
+
+func TestWriterPadByte(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    8000,
+		ByteRate:      8000,
+		BlockAlign:    1,
+		BitsPerSample: 8,
+	}
+	instrument := &Instrument{UnshiftedNote: 60, FineTune: -5, Gain: 3, LowNote: 0, HighNote: 127, LowVelocity: 1, HighVelocity: 127}
+
+	filename := "testdata/TestWriterPadByte.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	w.SetInstrument(instrument)
+	require.NoError(t, w.AddChunk("xodd", []byte{0x01, 0x02, 0x03}, AfterData))
+	require.NoError(t, w.WriteSamples([]Sample{{1}, {200}, {3}}))
+	require.NoError(t, w.Close())
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Zero(t, len(b)%2)
+	require.Equal(t, uint32(len(b)-8), binary.LittleEndian.Uint32(b[4:8]))
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, instrument, r.GetInstrument())
+	require.Equal(t, uint32(3), r.GetNumSamples())
+	samples, err := r.GetSamples(3)
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{1}, {200}, {3}}, samples)
+
+	var ids []string
+	for _, c := range r.GetChunks() {
+		ids = append(ids, c.ID)
+	}
+	require.Equal(t, []string{"fmt ", "inst", "data", "xodd"}, ids)
+	data, err := r.GetChunks()[3].Data()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x02, 0x03}, data)
+}
+
+func TestWriterCheckpoints(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    8000,
+		ByteRate:      16000,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+	filename := "testdata/TestWriterCheckpoints.wav"
+	defer os.Remove(filename)
+
+	// numSamplesOnDisk loads the file as written so far with a strict Reader.
+	numSamplesOnDisk := func() uint32 {
+		r := NewReader()
+		require.NoError(t, r.Open(filename))
+		defer r.Close()
+		require.NoError(t, r.Load())
+		return r.GetNumSamples()
+	}
+
+	t.Run("Frames", func(t *testing.T) {
+		w := NewWriter(format)
+		require.NoError(t, w.Open(filename))
+		w.SetInfo(&Info{Title: "live"})
+		w.EnableCheckpoints(CheckpointOptions{Frames: 4, Sync: true})
+
+		require.NoError(t, w.WriteSamples(make([]Sample, 3)))
+		require.Equal(t, uint32(0), numSamplesOnDisk())
+		require.NoError(t, w.WriteSamples(make([]Sample, 2)))
+		require.Equal(t, uint32(5), numSamplesOnDisk())
+		require.NoError(t, w.WriteSamples(make([]Sample, 1)))
+		require.Equal(t, uint32(5), numSamplesOnDisk())
+		require.NoError(t, w.Close())
+		require.Equal(t, uint32(6), numSamplesOnDisk())
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		w := NewWriter(format)
+		require.NoError(t, w.Open(filename))
+		w.EnableCheckpoints(CheckpointOptions{Interval: time.Nanosecond})
+
+		require.NoError(t, w.WriteSamples(make([]Sample, 3)))
+		time.Sleep(time.Millisecond)
+		require.NoError(t, w.WriteSamples(make([]Sample, 3)))
+		require.Equal(t, uint32(6), numSamplesOnDisk())
+		require.NoError(t, w.Close())
+	})
+}
+
+func TestWriterOpenAtomic(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    8000,
+		ByteRate:      16000,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "render.wav")
+
+	w := NewWriter(format)
+	require.NoError(t, w.OpenAtomic(filename))
+	require.NoError(t, w.WriteSamples([]Sample{{1}, {2}}))
+	_, err := os.Stat(filename)
+	require.True(t, os.IsNotExist(err))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Regexp(t, `^\.render\.wav\..+\.tmp$`, entries[0].Name())
+
+	require.NoError(t, w.Close())
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "render.wav", entries[0].Name())
+
+	r := NewReader()
+	require.NoError(t, r.Open(filename))
+	defer r.Close()
+	require.NoError(t, r.Load())
+	require.Equal(t, uint32(2), r.GetNumSamples())
+}
+
+func TestWriterAbort(t *testing.T) {
+	format := &Format{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   1,
+		SampleRate:    8000,
+		ByteRate:      16000,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "render.wav")
+	require.NoError(t, os.WriteFile(filename, []byte("previous"), 0644))
+
+	w := NewWriter(format)
+	require.NoError(t, w.OpenAtomic(filename))
+	require.NoError(t, w.WriteSamples([]Sample{{1}, {2}}))
+	require.NoError(t, w.Abort())
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, "previous", string(b))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	w = NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	require.NoError(t, w.WriteSamples([]Sample{{1}, {2}}))
+	require.NoError(t, w.Abort())
+	_, err = os.Stat(filename)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestWriterAbortAfterClose(t *testing.T) {
+	for _, atomic := range []bool{false, true} {
+		t.Run(fmt.Sprintf("atomic=%v", atomic), func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "render.wav")
+			w := NewWriter(NewPCMFormat(1, 8000, 16))
+			if atomic {
+				require.NoError(t, w.OpenAtomic(filename))
+			} else {
+				require.NoError(t, w.Open(filename))
+			}
+			require.NoError(t, w.WriteSamples([]Sample{{1}, {2}}))
+			require.NoError(t, w.Close())
+			require.NoError(t, w.Close())
+			require.NoError(t, w.Abort())
+
+			r := NewReader()
+			require.NoError(t, r.Open(filename))
+			defer r.Close()
+			require.NoError(t, r.Load())
+			require.Equal(t, uint32(2), r.GetNumSamples())
+		})
+	}
+}
+
+func TestWriterInvalidFormat(t *testing.T) {
+	format := NewPCMFormat(2, 48000, 16)
+	format.ByteRate = 128000
+
+	filename := "testdata/TestWriterInvalidFormat.wav"
+	w := NewWriter(format)
+	require.NoError(t, w.Open(filename))
+	defer os.Remove(filename)
+	err := w.WriteSamples(make([]Sample, 1))
+	require.ErrorIs(t, err, ErrInvalidFormat)
+	require.EqualError(t, err, "invalid ByteRate: 128000 does not match SampleRate * BlockAlign (192000)")
+	require.NoError(t, w.Abort())
+}
+
+func TestWriterWriteInterleaved(t *testing.T) {
+	t.Run("24", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "interleaved.wav")
+		w := NewWriter(NewPCMFormat(2, 48000, 24))
+		require.NoError(t, w.Open(path))
+		require.NoError(t, w.WriteInt16([]int16{1, -1}))
+		require.NoError(t, w.WriteInt32([]int32{0x12345600, -256}))
+		require.NoError(t, w.WriteFloat32([]float32{0.5, -2}))
+		require.NoError(t, w.Close())
+
+		r := NewReader()
+		require.NoError(t, r.Open(path))
+		defer r.Close()
+		require.NoError(t, r.Load())
+		samples, err := r.GetSamples(3)
+		require.NoError(t, err)
+		require.Equal(t, []Sample{{256, -256}, {0x123456, -1}, {0x400000, -0x800000}}, samples)
+	})
+
+	t.Run("8", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "interleaved.wav")
+		w := NewWriter(NewPCMFormat(1, 8000, 8))
+		require.NoError(t, w.Open(path))
+		require.NoError(t, w.WriteInt16([]int16{0, -32768, 32767}))
+		require.NoError(t, w.Close())
+
+		r := NewReader()
+		require.NoError(t, r.Open(path))
+		defer r.Close()
+		require.NoError(t, r.Load())
+		samples, err := r.GetSamples(3)
+		require.NoError(t, err)
+		require.Equal(t, []Sample{{128}, {0}, {255}}, samples)
+	})
+
+	t.Run("6Channels", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "interleaved.wav")
+		w := NewWriter(NewPCMFormat(6, 48000, 24))
+		w.EnablePeakChunk()
+		require.NoError(t, w.Open(path))
+		src := []int16{1, -1, 2, -2, 3, -3, 100, 200, 300, 400, 500, -32768}
+		require.ErrorIs(t, w.WriteSamples([]Sample{{1, -1}}), ErrTooManyChannels)
+		require.NoError(t, w.WriteInt16(src))
+		require.NoError(t, w.Close())
+
+		r := NewReader()
+		require.NoError(t, r.Open(path))
+		defer r.Close()
+		require.NoError(t, r.Load())
+		require.Equal(t, uint32(2), r.GetNumSamples())
+		require.Equal(t, float32(1), r.GetPeak().Channels[5].Value)
+
+		_, err := r.GetSamples(1)
+		require.ErrorIs(t, err, ErrTooManyChannels)
+		_, err = r.ReadSamples(make([]Sample, 1))
+		require.ErrorIs(t, err, ErrTooManyChannels)
+		require.Equal(t, uint32(2), r.GetNumSamplesLeft())
+
+		int16s := make([]int16, 6)
+		n, err := r.ReadInt16(int16s)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, src[:6], int16s)
+
+		int32s := make([]int32, 6)
+		n, err = r.ReadInt32(int32s)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, []int32{100 << 16, 200 << 16, 300 << 16, 400 << 16, 500 << 16, -1 << 31}, int32s)
+
+		n, err = r.ReadFloat32(make([]float32, 6))
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, 0, n)
+	})
+
+	t.Run("PartialFrame", func(t *testing.T) {
+		w := NewWriter(NewPCMFormat(2, 48000, 16))
+		require.NoError(t, w.Open(filepath.Join(t.TempDir(), "interleaved.wav")))
+		defer w.Abort()
+		err := w.WriteInt16([]int16{1, 2, 3})
+		require.ErrorIs(t, err, ErrPartialFrame)
+		require.EqualError(t, err, "interleaved buffer does not hold whole sample frames: 3 samples for 2 channels")
+	})
+}
+
+func TestWriterWriteAllocs(t *testing.T) {
+	w := NewWriter(NewPCMFormat(2, 48000, 24))
+	w.EnablePeakChunk()
+	require.NoError(t, w.Open(filepath.Join(t.TempDir(), "allocs.wav")))
+	defer w.Abort()
+
+	samples := make([]Sample, 256)
+	int16s := make([]int16, 512)
+	int32s := make([]int32, 512)
+	float32s := make([]float32, 512)
+	var (
+		runs int
+		err  error
+	)
+	check := func(e error) {
+		if err == nil {
+			err = e
+		}
+	}
+	allocs := testing.AllocsPerRun(50, func() {
+		runs++
+		check(w.WriteSamples(samples))
+		check(w.WriteInt16(int16s))
+		check(w.WriteInt32(int32s))
+		check(w.WriteFloat32(float32s))
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint32(runs*4*256), w.numWrittenSamples)
+	require.Zero(t, allocs)
+}
+
+// benchmarkWrite calls write, which writes blockFrames sample frames, b.N
+// times, starting over with a new file whenever the file grows too large.
+func benchmarkWrite(b *testing.B, format *Format, write func(w *Writer) error) {
+	const (
+		blockFrames = 1024
+		maxFileSize = 64 << 20
+	)
+	path := filepath.Join(b.TempDir(), "bench.wav")
+	w := NewWriter(format)
+	require.NoError(b, w.Open(path))
+	b.SetBytes(blockFrames * int64(format.BlockAlign))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if w.bw.GetOffset() > maxFileSize {
+			b.StopTimer()
+			require.NoError(b, w.Abort())
+			w = NewWriter(format)
+			require.NoError(b, w.Open(path))
+			b.StartTimer()
+		}
+		if err := write(w); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	require.NoError(b, w.Close())
+}
+
+func BenchmarkWriterWriteSamples(b *testing.B) {
+	for _, bf := range benchmarkFormats {
+		b.Run(bf.name, func(b *testing.B) {
+			samples := benchmarkSamples(bf.format, 1024)
+			benchmarkWrite(b, bf.format, func(w *Writer) error {
+				return w.WriteSamples(samples)
+			})
+		})
+	}
+}
+
+func BenchmarkWriterWrite(b *testing.B) {
+	var (
+		int16s   = make([]int16, 2*1024)
+		int32s   = make([]int32, 2*1024)
+		float32s = make([]float32, 2*1024)
+	)
+	for i := range int16s {
+		int16s[i] = int16(i * 7919)
+		int32s[i] = int32(i * 7919 << 16)
+		float32s[i] = float32(int16s[i]) / 32768
+	}
+	writes := []struct {
+		name  string
+		write func(w *Writer) error
+	}{
+		{"Int16", func(w *Writer) error { return w.WriteInt16(int16s) }},
+		{"Int32", func(w *Writer) error { return w.WriteInt32(int32s) }},
+		{"Float32", func(w *Writer) error { return w.WriteFloat32(float32s) }},
+	}
+	for _, wr := range writes {
+		for _, bf := range benchmarkFormats {
+			b.Run(wr.name+"/"+bf.name, func(b *testing.B) {
+				benchmarkWrite(b, bf.format, wr.write)
+			})
+		}
+	}
+}